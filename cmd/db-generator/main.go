@@ -12,8 +12,10 @@ import (
 )
 
 var (
-	source string
-	target string
+	source   string
+	target   string
+	phrases  string
+	variants string
 )
 
 const (
@@ -21,6 +23,8 @@ const (
 
 	SourceFileUsage = "Congkit source table file path"
 	TargetFileUsage = "Target database file path"
+	PhrasesUsage    = "Phrase list file path"
+	VariantsUsage   = "Unihan variants file path"
 	HelpUsage       = "Print usages"
 )
 
@@ -31,6 +35,12 @@ func init() {
 	flag.StringVar(&target, "target", "", TargetFileUsage)
 	flag.StringVar(&target, "t", "", TargetFileUsage)
 
+	flag.StringVar(&phrases, "phrases", "", PhrasesUsage)
+	flag.StringVar(&phrases, "p", "", PhrasesUsage)
+
+	flag.StringVar(&variants, "variants", "", VariantsUsage)
+	flag.StringVar(&variants, "v", "", VariantsUsage)
+
 	flag.BoolFunc("help", HelpUsage, helpFunc)
 	flag.BoolFunc("h", HelpUsage, helpFunc)
 }
@@ -40,7 +50,7 @@ func main() {
 
 	var err error
 
-	var sourceTable [][]string
+	var sourceTable []data.Entry
 	if source == "" {
 		fmt.Println("Using built-in Congkit radicals table")
 
@@ -61,6 +71,38 @@ func main() {
 		}
 	}
 
+	var phraseList []data.Entry
+	if phrases == "" {
+		fmt.Println("No phrase list provided, generating without phrases")
+	} else {
+		fmt.Println("Using provided phrase list ", phrases)
+
+		phrasesFile, err := os.Open(phrases)
+		if err != nil {
+			log.Fatalf("Failed opening phrase list file %s.\n%v\n", phrases, err)
+		}
+		phraseList, err = data.ReadPhrases(phrasesFile)
+		if err != nil {
+			log.Fatalf("Failed reading data from phrase list.\n%v\n", err)
+		}
+	}
+
+	var variantList [][]string
+	if variants == "" {
+		fmt.Println("No variant list provided, generating without variants")
+	} else {
+		fmt.Println("Using provided variant list ", variants)
+
+		variantsFile, err := os.Open(variants)
+		if err != nil {
+			log.Fatalf("Failed opening variant list file %s.\n%v\n", variants, err)
+		}
+		variantList, err = data.ReadVariants(variantsFile)
+		if err != nil {
+			log.Fatalf("Failed reading data from variant list.\n%v\n", err)
+		}
+	}
+
 	if target == "" {
 		fmt.Println("Using default target database file path")
 
@@ -72,7 +114,7 @@ func main() {
 	}
 	fmt.Printf("Target database file path: %s\n", target)
 
-	if err = db.Generate(sourceTable, target); err != nil {
+	if err = db.Generate(sourceTable, target, db.WithPhrases(phraseList), db.WithVariants(variantList)); err != nil {
 		log.Fatalf("Failed generating Congkit database file.\n%v\n", err)
 	}
 }
@@ -0,0 +1,13 @@
+package models
+
+// Keys of the database metadata
+const (
+	MetadataSchemaVersion = "schema_version"
+	MetadataGeneratedAt   = "generated_at"
+	MetadataTableRevision = "table_revision"
+)
+
+type Metadata struct {
+	Key   string
+	Value string
+}
@@ -0,0 +1,6 @@
+package models
+
+type Phrase struct {
+	Phrase    string
+	Frequency int
+}
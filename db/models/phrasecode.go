@@ -0,0 +1,7 @@
+package models
+
+type PhraseCode struct {
+	Phrase  string
+	Version int
+	Code    string
+}
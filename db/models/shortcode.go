@@ -0,0 +1,7 @@
+package models
+
+type ShortCode struct {
+	CharIdx  int
+	Code     string
+	Priority int
+}
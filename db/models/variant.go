@@ -0,0 +1,7 @@
+package models
+
+type Variant struct {
+	Char    rune
+	Variant rune
+	Kind    string
+}
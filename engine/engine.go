@@ -4,9 +4,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
 	"unicode/utf8"
 
+	"github.com/antonyho/go-congkit/learn"
+	"github.com/antonyho/go-congkit/ngram"
+	"github.com/antonyho/go-congkit/radical"
+	"github.com/antonyho/go-congkit/userdict"
+
 	// SQLite3 driver for the engine, the engine uses SQlite3.
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -62,6 +69,14 @@ func WithDatabase(path string) Option {
 	}
 }
 
+// WithLogger sets the logger for the engine events.
+// The default logger is slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(e *Engine) {
+		e.logger = logger
+	}
+}
+
 // Engine defaults
 const (
 	DefaultCongkitVersion = CongkitV5
@@ -73,9 +88,17 @@ type Engine struct {
 	OutputSimplified bool // Output Simplified Chinese word
 	Easy             bool // "Easy" input method mode
 	Prediction       bool // Predict word while typing
+	Fuzzy            bool // List typo corrected matches after exact matches
+	PhraseInput      bool // List phrases after characters in Candidates
 	dbPath           string
 	db               *sql.DB
+	inMemory         bool
 	query            string
+	logger           *slog.Logger
+	userDict         *userdict.Dictionary
+	learner          *learn.Learner
+	ngramModel       *ngram.Model
+	tables           map[string]bool // The optional tables found in the database
 }
 
 func New(options ...Option) *Engine {
@@ -83,6 +106,7 @@ func New(options ...Option) *Engine {
 		CongkitVersion:   DefaultCongkitVersion,
 		OutputSimplified: false,
 		dbPath:           DefaultDatabasePath,
+		logger:           slog.Default(),
 	}
 
 	for _, option := range options {
@@ -92,12 +116,23 @@ func New(options ...Option) *Engine {
 	if _, err := os.Stat(e.dbPath); err != nil && errors.Is(err, os.ErrNotExist) {
 		// The Congkit database does not exist, create an in-memory database.
 		// This is a constructor. Trying not to return error here.
-		e.db, _ = sql.Open("sqlite3", ":memory:")
+		e.logger.Warn("database not found, falling back to in-memory database", "path", e.dbPath)
+		e.inMemory = true
+		var err error
+		if e.db, err = sql.Open("sqlite3", ":memory:"); err != nil {
+			e.logger.Error("failed opening in-memory database", "error", err)
+		}
 	} else {
 		dsn := fmt.Sprintf(DatabaseDSNPattern, e.dbPath)
-		e.db, _ = sql.Open("sqlite3", dsn)
+		var err error
+		if e.db, err = sql.Open("sqlite3", dsn); err != nil {
+			e.logger.Error("failed opening database", "path", e.dbPath, "error", err)
+		} else {
+			e.logger.Debug("database opened", "path", e.dbPath)
+		}
 	}
 
+	e.detectTables()
 	e.determineQuery()
 
 	return e
@@ -111,28 +146,82 @@ func (e *Engine) Set(options ...Option) {
 	e.determineQuery()
 }
 
+// Encode lists the characters matching the radicals.
+// The radicals can be the keys 'a' to 'z', the radical glyphs or a mix of them.
+// A radical.Wildcard matches any one radical, e.g. "h*i".
+// The characters selected before come first when a learner is set.
 func (e *Engine) Encode(radicals string) (results []rune, err error) {
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		e.logger.Debug("invalid radicals", "radicals", radicals, "error", err)
+		return
+	}
+
+	results, err = e.encode(code)
+	if err != nil {
+		return
+	}
+	if e.Fuzzy {
+		if results, err = e.appendCorrections(results, code); err != nil {
+			return
+		}
+	}
+
+	if results, err = e.overlay(code, results); err != nil {
+		return
+	}
+
+	return e.rank(code, results)
+}
+
+func (e *Engine) encode(radicals string) (results []rune, err error) {
 	err = e.db.Ping()
 	if err != nil {
+		e.logger.Error("database unavailable", "error", err)
 		return
 	}
 
-	results = make([]rune, 0)
-	codes := radicals
+	rows, err := e.db.Query(e.queryOf(radicals), e.CongkitVersion, e.pattern(radicals))
+	if err != nil {
+		e.logger.Error("encode query failed", "radicals", radicals, "error", err)
+		return
+	}
+
+	return scanChars(rows)
+}
+
+func (e *Engine) Close() error {
+	return e.db.Close()
+}
+
+// pattern builds the radical pattern used by the current query
+// from the input radicals. Wildcards match any one radical.
+// The '%' and '_' in the radicals are escaped in a LIKE pattern.
+func (e *Engine) pattern(radicals string) string {
+	if !hasWildcard(radicals) && !e.Easy && !e.Prediction {
+		return radicals
+	}
+
+	codes := likeEscaper.Replace(radicals)
 	if e.Easy {
 		if len(radicals) > 1 {
-			codes = fmt.Sprintf("%c%%%c", radicals[0], radicals[1])
+			codes = likeEscaper.Replace(radicals[:1]) + "%" + likeEscaper.Replace(radicals[1:2])
 		}
 	} else if e.Prediction {
-		codes = fmt.Sprintf("%s%%", radicals)
+		codes = fmt.Sprintf("%s%%", codes)
 	}
 
-	rows, err := e.db.Query(e.query, e.CongkitVersion, codes)
-	if err != nil {
-		return
-	}
+	return strings.ReplaceAll(codes, string(radical.Wildcard), "_")
+}
+
+// likeEscaper escapes the special characters of a LIKE pattern with the ESCAPE character '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// scanChars reads the first character of each result row and closes the rows.
+func scanChars(rows *sql.Rows) (results []rune, err error) {
 	defer rows.Close()
 
+	results = make([]rune, 0)
 	for rows.Next() {
 		var s string
 		scanErr := rows.Scan(&s)
@@ -150,10 +239,6 @@ func (e *Engine) Encode(radicals string) (results []rune, err error) {
 	return
 }
 
-func (e *Engine) Close() error {
-	return e.db.Close()
-}
-
 func (e *Engine) determineQuery() {
 	if e.OutputSimplified {
 		if e.Easy {
@@ -1,10 +1,13 @@
 package engine_test
 
 import (
+	"bytes"
+	"log/slog"
 	"path"
 	"testing"
 
 	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/radical"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -226,6 +229,22 @@ func TestEngineInvalidDB(t *testing.T) {
 	assert.NoError(t, engine.Close())
 }
 
+func TestEngineWithLogger(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	notExistDbPath := path.Join(t.TempDir(), "notexist.db")
+	engine := congkit.New(congkit.WithDatabase(notExistDbPath), congkit.WithLogger(logger))
+	assert.Contains(t, logs.String(), "level=WARN")
+	assert.Contains(t, logs.String(), "falling back to in-memory database")
+
+	_, err := engine.Encode("oiar")
+	assert.Error(t, err)
+	assert.Contains(t, logs.String(), "level=ERROR")
+
+	assert.NoError(t, engine.Close())
+}
+
 func TestEngineSetOption(t *testing.T) {
 	engine := congkit.New()
 
@@ -252,3 +271,17 @@ func TestEngineEncodeForMultiRadicalSetsWord(t *testing.T) {
 
 	assert.NoError(t, engine.Close())
 }
+
+func TestEngineEncodeRadicalGlyphs(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	for _, radicals := range []string{"竹手戈", "竹q戈", "HQI"} {
+		results, err := engine.Encode(radicals)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []rune{'我', '牫', '𥫻'}, results)
+	}
+
+	_, err := engine.Encode("竹我")
+	assert.ErrorIs(t, err, radical.ErrUnknownGlyph)
+}
@@ -0,0 +1,19 @@
+package engine
+
+import "github.com/antonyho/go-congkit/radical"
+
+// Extendable reports whether any longer code starts with the radicals
+// in the Congkit version of the engine.
+func (e *Engine) Extendable(radicals string) (extendable bool, err error) {
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		return
+	}
+
+	err = e.db.QueryRow(HasLongerCode, e.CongkitVersion, code, code+"{").Scan(&extendable)
+	if err != nil {
+		e.logger.Error("longer code query failed", "radicals", code, "error", err)
+	}
+
+	return
+}
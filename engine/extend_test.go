@@ -0,0 +1,32 @@
+package engine_test
+
+import (
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineExtendable(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	var testCases = []struct {
+		radicals   string
+		extendable bool
+	}{
+		{"ykmh", true},
+		{"ykmhm", false},
+		{"oiar", false},
+		{"a", true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.radicals, func(t *testing.T) {
+			extendable, err := engine.Extendable(testCase.radicals)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.extendable, extendable)
+		})
+	}
+}
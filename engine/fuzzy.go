@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// MaxRadicals is the maximum number of radicals of a Congkit code.
+const MaxRadicals = 5
+
+// Candidate is a matching character with the code which produced it.
+type Candidate struct {
+	Char      rune
+	Radicals  string // The code matching the character
+	Corrected bool   // The code is a typo correction of the input
+}
+
+// WithFuzzy enables typo tolerant matching on Encode.
+// Characters of codes within one edit from the input
+// are listed after the exact matches.
+func WithFuzzy() Option {
+	return func(e *Engine) {
+		e.Fuzzy = true
+	}
+}
+
+// EncodeFuzzy encodes the radicals and the codes within edit distance 1
+// (substitution, transposition, insertion and deletion of a radical).
+// Exact matches come first, followed by the corrected matches
+// tagged with the corrected code.
+func (e *Engine) EncodeFuzzy(radicals string) (candidates []Candidate, err error) {
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		e.logger.Debug("invalid radicals", "radicals", radicals, "error", err)
+		return
+	}
+
+	exact, err := e.encode(code)
+	if err != nil {
+		return
+	}
+	if exact, err = e.overlay(code, exact); err != nil {
+		return
+	}
+
+	candidates = make([]Candidate, 0, len(exact))
+	seen := make(map[rune]bool, len(exact))
+	for _, char := range exact {
+		candidates = append(candidates, Candidate{Char: char, Radicals: code})
+		seen[char] = true
+	}
+
+	corrections, err := e.corrections(code)
+	if err != nil {
+		return
+	}
+	for _, correction := range corrections {
+		if seen[correction.Char] {
+			continue
+		}
+		hidden, hiddenErr := e.isHidden(string(correction.Char))
+		if hiddenErr != nil {
+			return candidates, hiddenErr
+		}
+		if !hidden {
+			candidates = append(candidates, correction)
+			seen[correction.Char] = true
+		}
+	}
+
+	return
+}
+
+// appendCorrections appends the characters of corrected codes
+// which are not in the results yet.
+func (e *Engine) appendCorrections(results []rune, radicals string) ([]rune, error) {
+	corrections, err := e.corrections(radicals)
+	if err != nil {
+		return results, err
+	}
+
+	seen := make(map[rune]bool, len(results))
+	for _, char := range results {
+		seen[char] = true
+	}
+	for _, correction := range corrections {
+		if !seen[correction.Char] {
+			results = append(results, correction.Char)
+			seen[correction.Char] = true
+		}
+	}
+
+	return results, nil
+}
+
+// corrections lists characters of the codes one edit away from the radicals.
+func (e *Engine) corrections(radicals string) ([]Candidate, error) {
+	variants := editVariants(radicals)
+	if len(variants) == 0 {
+		return nil, nil
+	}
+
+	rank := make(map[string]int, len(variants))
+	args := make([]any, 0, len(variants)+1)
+	args = append(args, e.CongkitVersion)
+	for i, variant := range variants {
+		rank[variant] = i
+		args = append(args, variant)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(variants)), ",")
+
+	query := GetCharsFromCongkitList
+	if e.OutputSimplified {
+		query = GetSimplifiedCharsFromCongkitList
+	}
+	rows, err := e.db.Query(fmt.Sprintf(query, placeholders), args...)
+	if err != nil {
+		e.logger.Error("fuzzy query failed", "radicals", radicals, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	corrections := make([]Candidate, 0)
+	for rows.Next() {
+		var s, code string
+		if scanErr := rows.Scan(&s, &code); scanErr != nil {
+			err = errors.Join(scanErr, err)
+			continue
+		}
+		char, _ := utf8.DecodeRuneInString(s)
+		if char != 0 {
+			corrections = append(corrections, Candidate{Char: char, Radicals: code, Corrected: true})
+		}
+	}
+	err = errors.Join(rows.Err(), err)
+
+	sort.SliceStable(corrections, func(i, j int) bool {
+		return rank[corrections[i].Radicals] < rank[corrections[j].Radicals]
+	})
+
+	return corrections, err
+}
+
+// editVariants generates the distinct codes within edit distance 1
+// of the radicals, in the order of transposition, deletion,
+// substitution and insertion. Only codes of radicals 'a' to 'z' are corrected.
+func editVariants(radicals string) []string {
+	if len(radicals) == 0 || len(radicals) > MaxRadicals || !isRadicals(radicals) {
+		return nil
+	}
+
+	variants := make([]string, 0)
+	seen := map[string]bool{radicals: true}
+	add := func(variant string) {
+		if len(variant) > 0 && len(variant) <= MaxRadicals && !seen[variant] {
+			seen[variant] = true
+			variants = append(variants, variant)
+		}
+	}
+
+	for i := 0; i+1 < len(radicals); i++ {
+		b := []byte(radicals)
+		b[i], b[i+1] = b[i+1], b[i]
+		add(string(b))
+	}
+	for i := range radicals {
+		add(radicals[:i] + radicals[i+1:])
+	}
+	for i := range radicals {
+		for r := byte('a'); r <= 'z'; r++ {
+			add(radicals[:i] + string(r) + radicals[i+1:])
+		}
+	}
+	for i := 0; i <= len(radicals); i++ {
+		for r := byte('a'); r <= 'z'; r++ {
+			add(radicals[:i] + string(r) + radicals[i:])
+		}
+	}
+
+	return variants
+}
+
+// isRadicals reports whether the code only consists of radicals 'a' to 'z'.
+func isRadicals(code string) bool {
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'a' || code[i] > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,82 @@
+package engine_test
+
+import (
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineEncodeFuzzy(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	var testCases = []struct {
+		name      string
+		radicals  string
+		corrected string
+	}{
+		{"transposition", "oair", "oiar"},
+		{"substitution", "oiae", "oiar"},
+		{"deletion", "oiarr", "oiar"},
+		{"insertion", "oir", "oiar"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			candidates, err := engine.EncodeFuzzy(testCase.radicals)
+			require.NoError(t, err)
+			assert.Contains(t, candidates, congkit.Candidate{
+				Char:      '倉',
+				Radicals:  testCase.corrected,
+				Corrected: true,
+			})
+		})
+	}
+}
+
+func TestEngineEncodeFuzzyExactFirst(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	candidates, err := engine.EncodeFuzzy("hqi")
+	require.NoError(t, err)
+	require.Greater(t, len(candidates), 3)
+
+	exact := make([]rune, 3)
+	for i, candidate := range candidates[:3] {
+		assert.False(t, candidate.Corrected)
+		assert.Equal(t, "hqi", candidate.Radicals)
+		exact[i] = candidate.Char
+	}
+	assert.ElementsMatch(t, []rune{'我', '牫', '𥫻'}, exact)
+	for _, candidate := range candidates[3:] {
+		assert.True(t, candidate.Corrected)
+	}
+}
+
+func TestEngineWithFuzzy(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	results, err := engine.Encode("oair")
+	require.NoError(t, err)
+	assert.NotContains(t, results, '倉')
+
+	engine.Set(congkit.WithFuzzy())
+	results, err = engine.Encode("oair")
+	require.NoError(t, err)
+	assert.Contains(t, results, '倉')
+}
+
+func BenchmarkEngineEncodeFuzzy(b *testing.B) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.EncodeFuzzy("ykmhm"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
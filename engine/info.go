@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/antonyho/go-congkit/db/models"
+)
+
+// ErrCharNotFound is returned on looking up a character not in the database.
+var ErrCharNotFound = errors.New("engine: character not found")
+
+// CharInfo is the facts of a character in the database.
+type CharInfo struct {
+	models.Character
+}
+
+// IsChinese reports whether the character is a Chinese character.
+func (c CharInfo) IsChinese() bool { return c.Chinese != 0 }
+
+// IsBig5 reports whether the character exists in the Big5 encoding.
+func (c CharInfo) IsBig5() bool { return c.Big5 != 0 }
+
+// IsHKSCS reports whether the character is in the Hong Kong Supplementary Character Set.
+func (c CharInfo) IsHKSCS() bool { return c.HKSCS != 0 }
+
+// IsZhuyin reports whether the character is in the Bopomofo/Zhuyin alphabet.
+func (c CharInfo) IsZhuyin() bool { return c.Zhuyin != 0 }
+
+// IsKanji reports whether the character is a Japanese Kanji.
+func (c CharInfo) IsKanji() bool { return c.Kanji != 0 }
+
+// IsHiragana reports whether the character is a Japanese Hiragana.
+func (c CharInfo) IsHiragana() bool { return c.Hiragana != 0 }
+
+// IsKatakana reports whether the character is a Japanese Katakana.
+func (c CharInfo) IsKatakana() bool { return c.Katakana != 0 }
+
+// IsPunctuation reports whether the character is a punctuation mark.
+func (c CharInfo) IsPunctuation() bool { return c.PunctuationMark != 0 }
+
+// IsSymbol reports whether the character is a miscellaneous symbol.
+func (c CharInfo) IsSymbol() bool { return c.MiscSymbol != 0 }
+
+// HasSimplified reports whether the character has a Simplified Chinese equivalent.
+func (c CharInfo) HasSimplified() bool { return c.Simplified != 0 }
+
+// Info looks up the facts of the character.
+// ErrCharNotFound is returned if the character is not in the database.
+func (e *Engine) Info(char rune) (info CharInfo, err error) {
+	var tc, sc string
+	c := &info.Character
+	err = e.db.QueryRow(GetCharInfo, string(char)).Scan(
+		&c.Idx, &tc, &sc, &c.Chinese, &c.Big5, &c.HKSCS, &c.Zhuyin,
+		&c.Kanji, &c.Hiragana, &c.Katakana, &c.PunctuationMark, &c.MiscSymbol,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return info, fmt.Errorf("%w: '%c'", ErrCharNotFound, char)
+	}
+	if err != nil {
+		e.logger.Error("character info query failed", "char", string(char), "error", err)
+		return
+	}
+
+	c.Tradition, _ = utf8.DecodeRuneInString(tc)
+	c.Simplified, _ = utf8.DecodeRuneInString(sc)
+
+	return
+}
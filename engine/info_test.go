@@ -0,0 +1,43 @@
+package engine_test
+
+import (
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineInfo(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	info, err := engine.Info('倉')
+	require.NoError(t, err)
+	assert.Equal(t, '倉', info.Tradition)
+	assert.Equal(t, '仓', info.Simplified)
+	assert.True(t, info.HasSimplified())
+	assert.True(t, info.IsChinese())
+	assert.True(t, info.IsBig5())
+	assert.False(t, info.IsPunctuation())
+	assert.False(t, info.IsHiragana())
+
+	info, err = engine.Info('、')
+	require.NoError(t, err)
+	assert.True(t, info.IsPunctuation())
+	assert.False(t, info.IsChinese())
+	assert.False(t, info.HasSimplified())
+
+	info, err = engine.Info('あ')
+	require.NoError(t, err)
+	assert.True(t, info.IsHiragana())
+	assert.False(t, info.IsKatakana())
+}
+
+func TestEngineInfoNotFound(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	_, err := engine.Info('A')
+	assert.ErrorIs(t, err, congkit.ErrCharNotFound)
+}
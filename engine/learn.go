@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/antonyho/go-congkit/learn"
+)
+
+// WithLearner ranks the results by the selections learned by the learner.
+// The results are ranked only after the user has given consent to the learner.
+// The engine does not close the learner.
+func WithLearner(learner *learn.Learner) Option {
+	return func(e *Engine) {
+		e.learner = learner
+	}
+}
+
+// Learn records the selection of the text for the radicals in the learner.
+// Nothing is recorded without a learner or without the consent of the user.
+func (e *Engine) Learn(radicals, text string) error {
+	if e.learner == nil {
+		return nil
+	}
+
+	err := e.learner.Record(radicals, text)
+	if errors.Is(err, learn.ErrNoConsent) {
+		return nil
+	}
+	if err != nil {
+		e.logger.Error("failed recording selection", "radicals", radicals, "error", err)
+	}
+
+	return err
+}
+
+// Unlearn reverses one selection of the text for the radicals recorded by Learn,
+// e.g. when the commit is undone.
+// Nothing is reversed without a learner or without the consent of the user.
+func (e *Engine) Unlearn(radicals, text string) error {
+	if e.learner == nil {
+		return nil
+	}
+
+	err := e.learner.Retract(radicals, text)
+	if errors.Is(err, learn.ErrNoConsent) {
+		return nil
+	}
+	if err != nil {
+		e.logger.Error("failed retracting selection", "radicals", radicals, "error", err)
+	}
+
+	return err
+}
+
+// rank sorts the characters of the code by the learned scores.
+// Characters without a score keep their order after the scored ones.
+func (e *Engine) rank(code string, results []rune) ([]rune, error) {
+	scores, err := e.scores(code)
+	if len(scores) == 0 {
+		return results, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return scores[string(results[i])] > scores[string(results[j])]
+	})
+
+	return results, nil
+}
+
+// rankTexts sorts the candidates of the code by the learned scores.
+func (e *Engine) rankTexts(code string, candidates []string) ([]string, error) {
+	scores, err := e.scores(code)
+	if len(scores) == 0 {
+		return candidates, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i]] > scores[candidates[j]]
+	})
+
+	return candidates, nil
+}
+
+// scores looks up the learned scores of the normalised code.
+func (e *Engine) scores(code string) (map[string]float64, error) {
+	if e.learner == nil {
+		return nil, nil
+	}
+
+	scores, err := e.learner.Scores(code)
+	if err != nil {
+		e.logger.Error("learned scores lookup failed", "radicals", code, "error", err)
+	}
+
+	return scores, err
+}
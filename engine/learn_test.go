@@ -0,0 +1,55 @@
+package engine_test
+
+import (
+	"path"
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/learn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineWithLearner(t *testing.T) {
+	learner, err := learn.Open(path.Join(t.TempDir(), "learn.db"))
+	require.NoError(t, err)
+	defer learner.Close()
+
+	engine := congkit.New(congkit.WithDatabase(TestDBPath), congkit.WithLearner(learner))
+	defer engine.Close()
+
+	original, err := engine.Encode("hqi")
+	require.NoError(t, err)
+	require.Len(t, original, 3)
+	selected := original[2]
+
+	require.NoError(t, engine.Learn("hqi", string(selected)), "no error without consent")
+	results, err := engine.Encode("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, original, results, "nothing is learned without consent")
+
+	require.NoError(t, learner.Consent())
+	require.NoError(t, engine.Learn("hqi", string(selected)))
+	results, err = engine.Encode("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, []rune{selected, original[0], original[1]}, results)
+
+	candidates, err := engine.Candidates("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, string(selected), candidates[0])
+
+	segments, err := engine.EncodeSequence("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, selected, segments[0].Results[0])
+
+	require.NoError(t, engine.Unlearn("hqi", string(selected)))
+	results, err = engine.Encode("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, original, results, "the selection is unlearned")
+
+	require.NoError(t, engine.Learn("hqi", string(selected)))
+	require.NoError(t, learner.Reset())
+	results, err = engine.Encode("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, original, results)
+}
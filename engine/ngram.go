@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/antonyho/go-congkit/ngram"
+)
+
+// MaxPredictions is the maximum number of next characters predicted by Predict.
+const MaxPredictions = 9
+
+// WithNgramModel predicts the next characters by the n-gram model
+// and ranks the candidates by the previous characters.
+// The engine does not close the model.
+func WithNgramModel(model *ngram.Model) Option {
+	return func(e *Engine) {
+		e.ngramModel = model
+	}
+}
+
+// Predict lists the likely next characters after the context text
+// by the n-gram model, or nothing without a model.
+func (e *Engine) Predict(context string) ([]string, error) {
+	if e.ngramModel == nil || context == "" {
+		return []string{}, nil
+	}
+
+	next, err := e.ngramModel.Next(context, MaxPredictions)
+	if err != nil {
+		e.logger.Error("n-gram prediction failed", "context", context, "error", err)
+	}
+
+	return next, err
+}
+
+// CandidatesAfter lists the candidates of the radicals as Candidates does,
+// ranking the likely characters after the context text first by the n-gram model.
+func (e *Engine) CandidatesAfter(radicals, context string) ([]string, error) {
+	candidates, err := e.Candidates(radicals)
+	if err != nil || e.ngramModel == nil || context == "" {
+		return candidates, err
+	}
+
+	scores, err := e.ngramModel.Scores(context)
+	if err != nil {
+		e.logger.Error("n-gram scores lookup failed", "context", context, "error", err)
+		return candidates, err
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i]] > scores[candidates[j]]
+	})
+
+	return candidates, nil
+}
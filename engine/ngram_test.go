@@ -0,0 +1,50 @@
+package engine_test
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/ngram"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineWithNgramModel(t *testing.T) {
+	model, err := ngram.Open(path.Join(t.TempDir(), "ngram.db"))
+	require.NoError(t, err)
+	defer model.Close()
+	require.NoError(t, model.Train(strings.NewReader("倉𥫻，倉𥫻。倉頡")))
+
+	engine := congkit.New(congkit.WithDatabase(TestDBPath), congkit.WithNgramModel(model))
+	defer engine.Close()
+
+	candidates, err := engine.CandidatesAfter("hqi", "倉")
+	require.NoError(t, err)
+	assert.Equal(t, "𥫻", candidates[0])
+	assert.ElementsMatch(t, []string{"我", "牫", "𥫻"}, candidates)
+
+	unranked, err := engine.Candidates("hqi")
+	require.NoError(t, err)
+	candidates, err = engine.CandidatesAfter("hqi", "")
+	require.NoError(t, err)
+	assert.Equal(t, unranked, candidates)
+
+	predictions, err := engine.Predict("倉")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"𥫻", "頡"}, predictions)
+
+	follows, err := engine.Associate("倉")
+	require.NoError(t, err)
+	assert.Subset(t, follows, []string{"𥫻", "頡"})
+}
+
+func TestEnginePredictWithoutModel(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	predictions, err := engine.Predict("倉")
+	require.NoError(t, err)
+	assert.Empty(t, predictions)
+}
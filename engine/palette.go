@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// SymbolPrefixes are the codes of the symbols and the punctuations in the table.
+// "za" is the catch-all code of Congkit version 5.
+var SymbolPrefixes = []string{"zx", "yyy", "za"}
+
+// SymbolCategory is a category of the symbols in the palette.
+type SymbolCategory string
+
+// Symbol categories in the order of the palette
+const (
+	CategoryPunctuation SymbolCategory = "punctuation"
+	CategoryBrackets    SymbolCategory = "brackets"
+	CategoryArrows      SymbolCategory = "arrows"
+	CategoryShapes      SymbolCategory = "shapes"
+	CategoryZhuyin      SymbolCategory = "zhuyin"
+	CategoryUnits       SymbolCategory = "units"
+	CategoryNumerals    SymbolCategory = "numerals"
+	CategoryOther       SymbolCategory = "other"
+)
+
+var symbolCategories = []SymbolCategory{
+	CategoryPunctuation,
+	CategoryBrackets,
+	CategoryArrows,
+	CategoryShapes,
+	CategoryZhuyin,
+	CategoryUnits,
+	CategoryNumerals,
+	CategoryOther,
+}
+
+// Symbol is a symbol with its code.
+type Symbol struct {
+	Char     rune
+	Radicals string
+}
+
+// SymbolGroup is the symbols of a category.
+type SymbolGroup struct {
+	Category SymbolCategory
+	Symbols  []Symbol
+}
+
+// SymbolPalette groups the symbols of the codes starting with the prefix into categories,
+// e.g. "z" or "yyy", so that the symbols can be browsed without the full codes.
+// The prefix can be a part or an extension of any of the SymbolPrefixes,
+// an empty prefix lists all the symbols. Other prefixes list no symbols.
+// The symbols of a category are in the order of their codes.
+func (e *Engine) SymbolPalette(prefix string) (groups []SymbolGroup, err error) {
+	prefix, err = radical.Normalize(prefix)
+	if err != nil {
+		return
+	}
+
+	symbols := make(map[SymbolCategory][]Symbol)
+	seen := make(map[Symbol]bool)
+	for _, symbolPrefix := range SymbolPrefixes {
+		var code string
+		switch {
+		case strings.HasPrefix(symbolPrefix, prefix):
+			code = symbolPrefix
+		case strings.HasPrefix(prefix, symbolPrefix):
+			code = prefix
+		default:
+			continue
+		}
+		if err = e.addSymbols(symbols, seen, code); err != nil {
+			return nil, err
+		}
+	}
+
+	groups = make([]SymbolGroup, 0, len(symbols))
+	for _, category := range symbolCategories {
+		if len(symbols[category]) > 0 {
+			groups = append(groups, SymbolGroup{Category: category, Symbols: symbols[category]})
+		}
+	}
+
+	return
+}
+
+// addSymbols categorises the characters of the codes starting with the code.
+func (e *Engine) addSymbols(symbols map[SymbolCategory][]Symbol, seen map[Symbol]bool, code string) error {
+	rows, err := e.db.Query(GetSymbolsFromPrefix, e.CongkitVersion, code, code+"{")
+	if err != nil {
+		e.logger.Error("symbol query failed", "radicals", code, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			s      string
+			symbol Symbol
+			zhuyin bool
+		)
+		if err := rows.Scan(&s, &symbol.Radicals, &zhuyin); err != nil {
+			return err
+		}
+		char, size := utf8.DecodeRuneInString(s)
+		if size == 0 || char == 0 {
+			continue
+		}
+		symbol.Char = char
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		category := categorize(symbol.Char, zhuyin)
+		symbols[category] = append(symbols[category], symbol)
+	}
+
+	return rows.Err()
+}
+
+// categorize finds the category of the symbol by its Unicode properties.
+func categorize(char rune, zhuyin bool) SymbolCategory {
+	switch {
+	case zhuyin || unicode.Is(unicode.Bopomofo, char) || unicode.Is(unicode.Lm, char):
+		return CategoryZhuyin
+	case char >= 0x2190 && char <= 0x21ff:
+		return CategoryArrows
+	case unicode.In(char, unicode.Ps, unicode.Pe, unicode.Pi, unicode.Pf):
+		return CategoryBrackets
+	case char >= 0x2500 && char <= 0x27bf:
+		// Box drawing, block elements, geometric shapes, miscellaneous symbols and dingbats
+		return CategoryShapes
+	case unicode.In(char, unicode.Sc, unicode.Sm) || (char >= 0x2100 && char <= 0x214f) || char == '°':
+		// Currencies, math signs, letterlike symbols and the degree sign
+		return CategoryUnits
+	case unicode.In(char, unicode.Nl, unicode.Nd, unicode.No):
+		return CategoryNumerals
+	case unicode.IsPunct(char) || unicode.IsSpace(char):
+		return CategoryPunctuation
+	}
+
+	return CategoryOther
+}
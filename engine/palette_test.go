@@ -0,0 +1,68 @@
+package engine_test
+
+import (
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// paletteChars maps the categories of the palette to their symbols.
+func paletteChars(groups []congkit.SymbolGroup) map[congkit.SymbolCategory]string {
+	chars := make(map[congkit.SymbolCategory]string)
+	for _, group := range groups {
+		for _, symbol := range group.Symbols {
+			chars[group.Category] += string(symbol.Char)
+		}
+	}
+
+	return chars
+}
+
+func TestEngineSymbolPalette(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	groups, err := engine.SymbolPalette("yyy")
+	require.NoError(t, err)
+	chars := paletteChars(groups)
+	assert.Contains(t, chars[congkit.CategoryArrows], "→←↓")
+	assert.Contains(t, chars[congkit.CategoryShapes], "△▲▽▼")
+	assert.Contains(t, chars[congkit.CategoryBrackets], "「」『』")
+	assert.Contains(t, chars[congkit.CategoryZhuyin], "ㄅㄆㄇ")
+	assert.Contains(t, chars[congkit.CategoryZhuyin], "ˊˇˋ")
+	assert.Contains(t, chars[congkit.CategoryUnits], "℃℉°")
+	assert.Contains(t, chars[congkit.CategoryNumerals], "〡〢〣")
+
+	assert.Equal(t, congkit.CategoryPunctuation, groups[0].Category, "groups are in the order of the categories")
+}
+
+func TestEngineSymbolPaletteDrillDown(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	groups, err := engine.SymbolPalette("z")
+	require.NoError(t, err)
+	chars := paletteChars(groups)
+	assert.Contains(t, chars[congkit.CategoryPunctuation], "，、。")
+	assert.Contains(t, chars[congkit.CategoryShapes], "■□", "za is in the palette of version 5")
+
+	groups, err = engine.SymbolPalette("yyyx")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, congkit.CategoryZhuyin, groups[0].Category)
+
+	groups, err = engine.SymbolPalette("hqi")
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+}
+
+func TestEngineSymbolPaletteV3(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath), congkit.WithCongkitV3())
+	defer engine.Close()
+
+	groups, err := engine.SymbolPalette("za")
+	require.NoError(t, err)
+	assert.Empty(t, groups, "za is not a symbol code of version 3")
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// WithPhraseInput lists the phrases of the radicals after the characters in Candidates.
+func WithPhraseInput() Option {
+	return func(e *Engine) {
+		e.PhraseInput = true
+	}
+}
+
+// EncodePhrase lists the phrases of the radicals ranked by frequency.
+// The radicals of a phrase are the first radical of each character,
+// or the first and the last radical of each character,
+// e.g. "he" or "haeu" for "香港". Like the characters, the phrase codes
+// have at most MaxRadicals radicals.
+// Databases generated before the phrases were imported have no phrases.
+func (e *Engine) EncodePhrase(radicals string) (phrases []string, err error) {
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		e.logger.Debug("invalid radicals", "radicals", radicals, "error", err)
+		return
+	}
+	if !e.tables["phrase_codes"] {
+		return []string{}, nil
+	}
+
+	rows, err := e.db.Query(GetPhrasesFromCongkit, e.CongkitVersion, code)
+	if err != nil {
+		e.logger.Error("phrase query failed", "radicals", code, "error", err)
+		return
+	}
+
+	return scanStrings(rows)
+}
+
+// Candidates lists the characters matching the radicals as Encode does,
+// followed by the phrases of the radicals when the phrase input is on.
+// All the texts of the user dictionary are listed first,
+// then the candidates are ranked by the learned selections.
+// The user dictionary and the learned selections are applied once
+// to the characters and the phrases together.
+func (e *Engine) Candidates(radicals string) (candidates []string, err error) {
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		e.logger.Debug("invalid radicals", "radicals", radicals, "error", err)
+		return
+	}
+
+	chars, err := e.encode(code)
+	if err != nil {
+		return
+	}
+	if e.Fuzzy {
+		if chars, err = e.appendCorrections(chars, code); err != nil {
+			return
+		}
+	}
+	candidates = make([]string, len(chars))
+	for i, char := range chars {
+		candidates[i] = string(char)
+	}
+	if e.PhraseInput {
+		phrases, phraseErr := e.EncodePhrase(code)
+		if phraseErr != nil {
+			return candidates, phraseErr
+		}
+		candidates = append(candidates, phrases...)
+	}
+
+	if candidates, err = e.overlayTexts(code, candidates); err != nil {
+		return
+	}
+
+	return e.rankTexts(code, candidates)
+}
+
+// Associate lists the text following the prefix in the phrases,
+// ranked by the phrase frequency, e.g. "港", "味" and "蕉" for "香",
+// followed by the characters predicted by the n-gram model.
+// Databases generated before the phrases were imported only give the predictions.
+func (e *Engine) Associate(prefix string) (follows []string, err error) {
+	follows = make([]string, 0)
+	if prefix == "" {
+		return
+	}
+
+	if e.tables["phrases"] {
+		rows, queryErr := e.db.Query(GetPhrasesFromPrefix, prefix, prefix+string(utf8.MaxRune))
+		if queryErr != nil {
+			e.logger.Error("associated phrase query failed", "prefix", prefix, "error", queryErr)
+			return follows, queryErr
+		}
+
+		phrases, scanErr := scanStrings(rows)
+		for _, phrase := range phrases {
+			follows = append(follows, strings.TrimPrefix(phrase, prefix))
+		}
+		if scanErr != nil {
+			return follows, scanErr
+		}
+	}
+
+	predictions, err := e.Predict(prefix)
+	for _, prediction := range predictions {
+		if !slices.Contains(follows, prediction) {
+			follows = append(follows, prediction)
+		}
+	}
+
+	return
+}
+
+// scanStrings reads the text of each result row and closes the rows.
+func scanStrings(rows *sql.Rows) (results []string, err error) {
+	defer rows.Close()
+
+	results = make([]string, 0)
+	for rows.Next() {
+		var s string
+		if scanErr := rows.Scan(&s); scanErr != nil {
+			err = errors.Join(scanErr, err)
+			continue
+		}
+		results = append(results, s)
+	}
+	err = errors.Join(rows.Err(), err)
+
+	return
+}
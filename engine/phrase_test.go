@@ -0,0 +1,100 @@
+package engine_test
+
+import (
+	"database/sql"
+	"path"
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineAssociate(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	follows, err := engine.Associate("香")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(follows), 3)
+	assert.Equal(t, []string{"港", "味", "蕉"}, follows[:3])
+	assert.Contains(t, follows, "港人")
+
+	follows, err = engine.Associate("香港")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"人"}, follows)
+
+	follows, err = engine.Associate("𥫻")
+	require.NoError(t, err)
+	assert.Empty(t, follows)
+}
+
+func TestEngineEncodePhrase(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	for _, radicals := range []string{"he", "haeu", "竹水"} {
+		phrases, err := engine.EncodePhrase(radicals)
+		require.NoError(t, err)
+		assert.Contains(t, phrases, "香港")
+	}
+
+	phrases, err := engine.EncodePhrase("xxxx")
+	require.NoError(t, err)
+	assert.Empty(t, phrases)
+}
+
+func TestEngineCandidatesWithPhraseInput(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	candidates, err := engine.Candidates("hqi")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"我", "牫", "𥫻"}, candidates)
+
+	candidates, err = engine.Candidates("haeu")
+	require.NoError(t, err)
+	assert.NotContains(t, candidates, "香港")
+
+	engine.Set(congkit.WithPhraseInput())
+	candidates, err = engine.Candidates("haeu")
+	require.NoError(t, err)
+	assert.Contains(t, candidates, "香港")
+}
+
+func TestEnginePhrasesWithoutTables(t *testing.T) {
+	// A database generated before the phrases were imported
+	oldDbPath := path.Join(t.TempDir(), "old.db")
+	oldDb, err := sql.Open("sqlite3", oldDbPath)
+	require.NoError(t, err)
+	for _, query := range []string{
+		db.CreateCharsTableQuery,
+		db.CreateRadicalsTableQuery,
+		`INSERT INTO characters (idx, tc) VALUES (0, '我');`,
+		`INSERT INTO radicals (char_idx, version, radical) VALUES (0, 5, 'hqi');`,
+	} {
+		_, err := oldDb.Exec(query)
+		require.NoError(t, err)
+	}
+	require.NoError(t, oldDb.Close())
+
+	for _, dbPath := range []string{oldDbPath, path.Join(t.TempDir(), "notexist.db")} {
+		engine := congkit.New(congkit.WithDatabase(dbPath), congkit.WithPhraseInput())
+		defer engine.Close()
+
+		phrases, err := engine.EncodePhrase("he")
+		require.NoError(t, err)
+		assert.Empty(t, phrases)
+
+		follows, err := engine.Associate("香")
+		require.NoError(t, err)
+		assert.Empty(t, follows)
+	}
+
+	engine := congkit.New(congkit.WithDatabase(oldDbPath), congkit.WithPhraseInput())
+	defer engine.Close()
+	candidates, err := engine.Candidates("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"我"}, candidates)
+}
@@ -0,0 +1,13 @@
+package engine
+
+// Punctuation lists the full-width characters of the short code key,
+// e.g. '、' and '，' for ',', in the priority order of the table.
+func (e *Engine) Punctuation(key rune) ([]rune, error) {
+	rows, err := e.db.Query(GetCharsFromShortCode, string(key))
+	if err != nil {
+		e.logger.Error("short code query failed", "key", string(key), "error", err)
+		return nil, err
+	}
+
+	return scanChars(rows)
+}
@@ -0,0 +1,42 @@
+package engine_test
+
+import (
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnginePunctuation(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	var testCases = []struct {
+		key      rune
+		expected []rune
+	}{
+		{',', []rune{'、', '，'}},
+		{'.', []rune{'。', '．'}},
+		{'\'', []rune{'「', '」', '〈', '〉', '＇'}},
+		{'"', []rune{'『', '』', '《', '》', '＂'}},
+		{'a', []rune{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(string(testCase.key), func(t *testing.T) {
+			results, err := engine.Punctuation(testCase.key)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, results)
+		})
+	}
+}
+
+func TestEngineEncodeShortCode(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	results, err := engine.Encode(",")
+	require.NoError(t, err)
+	assert.Empty(t, results, "short codes are not radicals")
+}
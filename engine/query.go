@@ -10,13 +10,13 @@ const (
 	GetCharFromQuick = `
 	SELECT tc FROM characters LEFT JOIN radicals 
 	ON (characters.idx = radicals.char_idx) 
-	WHERE radicals.version = ? AND radicals.radical LIKE ?
+	WHERE radicals.version = ? AND radicals.radical LIKE ? ESCAPE '\'
 	`
 
 	GetCharWithPrediction = `
 	SELECT tc FROM characters LEFT JOIN radicals 
 	ON (characters.idx = radicals.char_idx) 
-	WHERE radicals.version = ? AND radicals.radical LIKE ?
+	WHERE radicals.version = ? AND radicals.radical LIKE ? ESCAPE '\'
 	`
 
 	GetSimplifiedCharFromCongkit = `
@@ -28,12 +28,98 @@ const (
 	GetSimplifiedCharFromQuick = `
 	SELECT sc FROM characters LEFT JOIN radicals 
 	ON (characters.idx = radicals.char_idx) 
-	WHERE radicals.version = ? AND radicals.radical LIKE ?
+	WHERE radicals.version = ? AND radicals.radical LIKE ? ESCAPE '\'
 	`
 
 	GetSimplifiedCharWithPrediction = `
 	SELECT sc FROM characters LEFT JOIN radicals 
 	ON (characters.idx = radicals.char_idx) 
-	WHERE radicals.version = ? AND radicals.radical LIKE ?
+	WHERE radicals.version = ? AND radicals.radical LIKE ? ESCAPE '\'
 	`
 )
+
+// Queries matching any of a list of radicals.
+// The placeholders of the radicals list must be filled in before use.
+const (
+	GetCharsFromCongkitList = `
+	SELECT tc, radicals.radical FROM characters LEFT JOIN radicals 
+	ON (characters.idx = radicals.char_idx) 
+	WHERE radicals.version = ? AND radicals.radical IN (%s)
+	`
+
+	GetSimplifiedCharsFromCongkitList = `
+	SELECT sc, radicals.radical FROM characters LEFT JOIN radicals 
+	ON (characters.idx = radicals.char_idx) 
+	WHERE radicals.version = ? AND radicals.radical IN (%s)
+	`
+)
+
+// Queries for the engine introspection.
+const (
+	HasTable = `SELECT COUNT(ALL) FROM sqlite_master WHERE type = 'table' AND name = ?`
+
+	CountChars = `SELECT COUNT(ALL) FROM characters`
+
+	CountCharsAndCodesPerVersion = `
+	SELECT version, COUNT(DISTINCT char_idx), COUNT(DISTINCT radical) 
+	FROM radicals GROUP BY version
+	`
+
+	GetMetadata = `SELECT key, value FROM metadata`
+)
+
+// GetCharInfo merges the entries of a character,
+// some characters have an extra entry for their short code.
+const GetCharInfo = `
+	SELECT MIN(idx), tc, MAX(sc), MAX(chinese), MAX(big5), MAX(hkcsc), MAX(zhuyin), 
+	MAX(kanji), MAX(hiragana), MAX(katakana), MAX(punctuation), MAX(symbol) 
+	FROM characters WHERE tc = ? GROUP BY tc
+	`
+
+// HasLongerCode looks for a code between the radicals and the radicals followed by '{',
+// which is the character after 'z'. Those are the longer codes starting with the radicals.
+const HasLongerCode = `
+	SELECT EXISTS (
+		SELECT 1 FROM radicals 
+		WHERE version = ? AND radical > ? AND radical < ?
+	)
+	`
+
+// GetCharsFromShortCode lists the characters of a short code in priority order.
+const GetCharsFromShortCode = `
+	SELECT tc FROM characters JOIN shortcodes 
+	ON (characters.idx = shortcodes.char_idx) 
+	WHERE shortcodes.code = ? 
+	ORDER BY shortcodes.priority DESC, characters.idx
+	`
+
+// GetPhrasesFromPrefix lists the phrases between the prefix and the prefix followed by
+// the last Unicode code point, which are the longer phrases starting with the prefix.
+const GetPhrasesFromPrefix = `
+	SELECT phrase FROM phrases 
+	WHERE phrase > ? AND phrase < ? 
+	ORDER BY frequency DESC, phrase
+	`
+
+// GetPhrasesFromCongkit lists the phrases of a code ranked by frequency.
+const GetPhrasesFromCongkit = `
+	SELECT DISTINCT phrases.phrase FROM phrases JOIN phrase_codes 
+	ON (phrases.phrase = phrase_codes.phrase) 
+	WHERE phrase_codes.version = ? AND phrase_codes.code = ? 
+	ORDER BY phrases.frequency DESC, phrases.phrase
+	`
+
+// GetSymbolsFromPrefix lists the characters of the codes from the prefix inclusive
+// to the prefix followed by '{', which are the codes starting with the prefix.
+const GetSymbolsFromPrefix = `
+	SELECT tc, radicals.radical, characters.zhuyin FROM characters JOIN radicals 
+	ON (characters.idx = radicals.char_idx) 
+	WHERE radicals.version = ? AND radicals.radical >= ? AND radicals.radical < ?
+	ORDER BY radicals.radical, characters.idx
+	`
+
+// GetVariants lists the variant characters of a character.
+const GetVariants = `SELECT variant FROM variants WHERE char = ? ORDER BY variant`
+
+// GetVariantsOfChars lists the variant characters of a list of characters.
+const GetVariantsOfChars = `SELECT char, variant FROM variants WHERE char IN (%s)`
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// SequenceDelimiters are the characters, besides white spaces,
+// which separate the codes in a sequence.
+const SequenceDelimiters = ",;|"
+
+// SegmentResult is the encoding result of one code in a sequence.
+type SegmentResult struct {
+	Radicals string // The code of this segment
+	Results  []rune // Matching characters of the code
+	Err      error  // Error on encoding this segment only
+}
+
+// EncodeSequence encodes a sequence of codes separated by white spaces
+// or any of the SequenceDelimiters, e.g. "oiar hqi ykmhm".
+// All segments share the same prepared query within one transaction.
+// The returned error is only for failures affecting the whole sequence,
+// errors of individual segments are reported in their SegmentResult.
+func (e *Engine) EncodeSequence(sequence string) (segments []SegmentResult, err error) {
+	codes := SplitSequence(sequence)
+	segments = make([]SegmentResult, 0, len(codes))
+	if len(codes) == 0 {
+		return
+	}
+
+	if err = e.db.Ping(); err != nil {
+		e.logger.Error("database unavailable", "error", err)
+		return
+	}
+
+	tx, err := e.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting db transaction. %w", err)
+	}
+	// The transaction only reads, nothing to commit.
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(e.query)
+	if err != nil {
+		e.logger.Error("failed preparing encode query", "error", err)
+		return nil, fmt.Errorf("error preparing encode query statement. %w", err)
+	}
+	defer stmt.Close()
+
+	for _, code := range codes {
+		segment := SegmentResult{Radicals: code}
+		code, normalizeErr := radical.Normalize(code)
+		if normalizeErr != nil {
+			e.logger.Debug("invalid radicals", "radicals", segment.Radicals, "error", normalizeErr)
+			segment.Err = normalizeErr
+			segments = append(segments, segment)
+			continue
+		}
+		if hasWildcard(code) {
+			// The prepared query only matches the exact codes.
+			segment.Results, segment.Err = e.encode(code)
+		} else if rows, queryErr := stmt.Query(e.CongkitVersion, e.pattern(code)); queryErr != nil {
+			e.logger.Error("encode query failed", "radicals", code, "error", queryErr)
+			segment.Err = queryErr
+		} else {
+			segment.Results, segment.Err = scanChars(rows)
+		}
+		if segment.Err == nil && e.Fuzzy {
+			segment.Results, segment.Err = e.appendCorrections(segment.Results, code)
+		}
+		if segment.Err == nil {
+			segment.Results, segment.Err = e.overlay(code, segment.Results)
+		}
+		if segment.Err == nil {
+			segment.Results, segment.Err = e.rank(code, segment.Results)
+		}
+		segments = append(segments, segment)
+	}
+
+	return
+}
+
+// SplitSequence splits a sequence of codes into individual codes.
+// Empty segments are dropped.
+func SplitSequence(sequence string) []string {
+	return strings.FieldsFunc(sequence, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(SequenceDelimiters, r)
+	})
+}
@@ -0,0 +1,71 @@
+package engine_test
+
+import (
+	"path"
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/radical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineEncodeSequence(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	segments, err := engine.EncodeSequence("oiar hqi,ykmhm;\tabcd")
+	require.NoError(t, err)
+	require.Len(t, segments, 4)
+
+	expected := []struct {
+		radicals string
+		results  []rune
+	}{
+		{"oiar", []rune{'倉'}},
+		{"hqi", []rune{'我', '牫', '𥫻'}},
+		{"ykmhm", []rune{'產'}},
+		{"abcd", []rune{}},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.radicals, segments[i].Radicals)
+		assert.NoError(t, segments[i].Err)
+		assert.ElementsMatch(t, e.results, segments[i].Results)
+	}
+}
+
+func TestEngineEncodeSequenceSegmentError(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	segments, err := engine.EncodeSequence("人戈日口 竹我 hqi")
+	require.NoError(t, err)
+	require.Len(t, segments, 3)
+
+	assert.NoError(t, segments[0].Err)
+	assert.Equal(t, []rune{'倉'}, segments[0].Results)
+	assert.ErrorIs(t, segments[1].Err, radical.ErrUnknownGlyph)
+	assert.NoError(t, segments[2].Err)
+	assert.Len(t, segments[2].Results, 3)
+}
+
+func TestEngineEncodeEmptySequence(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	segments, err := engine.EncodeSequence(" ,; ")
+	assert.NoError(t, err)
+	assert.Empty(t, segments)
+}
+
+func TestEngineEncodeSequenceInvalidDB(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(path.Join(t.TempDir(), "notexist.db")))
+	defer engine.Close()
+
+	_, err := engine.EncodeSequence("oiar hqi")
+	assert.Error(t, err)
+}
+
+func TestSplitSequence(t *testing.T) {
+	assert.Equal(t, []string{"oiar", "hqi", "ykmhm"}, congkit.SplitSequence(" oiar\nhqi | ykmhm "))
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/antonyho/go-congkit/db/models"
+)
+
+// Stats describes the database opened by the engine.
+type Stats struct {
+	DatabasePath  string                          // Path of the database file
+	InMemory      bool                            // The database file was not found, an empty in-memory database is used
+	Characters    int                             // Number of characters
+	Versions      map[CongkitVersion]VersionStats // Characters and codes of each Congkit version
+	SchemaVersion int                             // Schema version recorded by the generator, 0 if not recorded
+	GeneratedAt   time.Time                       // Generation time recorded by the generator, zero if not recorded
+	TableRevision string                          // Digest of the source table recorded by the generator
+}
+
+// VersionStats counts the characters and codes of a Congkit version.
+type VersionStats struct {
+	Characters int // Number of characters having a code
+	Codes      int // Number of distinct codes
+}
+
+// Stats inspects the database opened by the engine.
+// An empty database, like the in-memory fallback, gives zero counts.
+func (e *Engine) Stats() (stats Stats, err error) {
+	stats = Stats{
+		DatabasePath: e.dbPath,
+		InMemory:     e.inMemory,
+		Versions:     make(map[CongkitVersion]VersionStats),
+	}
+
+	if err = e.db.Ping(); err != nil {
+		e.logger.Error("database unavailable", "error", err)
+		return
+	}
+
+	if ok, err := e.hasTable("characters"); err != nil || !ok {
+		return stats, err
+	}
+	if err = e.db.QueryRow(CountChars).Scan(&stats.Characters); err != nil {
+		return stats, fmt.Errorf("error counting characters. %w", err)
+	}
+
+	rows, err := e.db.Query(CountCharsAndCodesPerVersion)
+	if err != nil {
+		return stats, fmt.Errorf("error counting codes. %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version CongkitVersion
+		var versionStats VersionStats
+		if err = rows.Scan(&version, &versionStats.Characters, &versionStats.Codes); err != nil {
+			return stats, fmt.Errorf("error counting codes. %w", err)
+		}
+		stats.Versions[version] = versionStats
+	}
+	if err = rows.Err(); err != nil {
+		return stats, fmt.Errorf("error counting codes. %w", err)
+	}
+
+	err = e.readMetadata(&stats)
+
+	return
+}
+
+// readMetadata fills in the metadata recorded by the generator.
+// Databases generated before the metadata was recorded are left as they are.
+func (e *Engine) readMetadata(stats *Stats) error {
+	if ok, err := e.hasTable("metadata"); err != nil || !ok {
+		return err
+	}
+
+	rows, err := e.db.Query(GetMetadata)
+	if err != nil {
+		return fmt.Errorf("error reading metadata. %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var metadata models.Metadata
+		if err := rows.Scan(&metadata.Key, &metadata.Value); err != nil {
+			return fmt.Errorf("error reading metadata. %w", err)
+		}
+		switch metadata.Key {
+		case models.MetadataSchemaVersion:
+			stats.SchemaVersion, err = strconv.Atoi(metadata.Value)
+		case models.MetadataGeneratedAt:
+			stats.GeneratedAt, err = time.Parse(time.RFC3339, metadata.Value)
+		case models.MetadataTableRevision:
+			stats.TableRevision = metadata.Value
+		}
+		if err != nil {
+			return fmt.Errorf("malformed metadata '%s'. %w", metadata.Key, err)
+		}
+	}
+
+	return rows.Err()
+}
+
+// optionalTables are the tables missing in the databases generated before their data were imported.
+var optionalTables = []string{"phrases", "phrase_codes", "variants"}
+
+// detectTables looks up the optional tables once, so that the lookups
+// on each key press skip the missing tables without asking the database.
+func (e *Engine) detectTables() {
+	e.tables = make(map[string]bool, len(optionalTables))
+	if e.db == nil {
+		return
+	}
+
+	for _, name := range optionalTables {
+		ok, err := e.hasTable(name)
+		if err != nil {
+			e.logger.Error("table lookup failed", "table", name, "error", err)
+		}
+		e.tables[name] = ok
+	}
+}
+
+// hasTable reports whether the table exists in the database.
+func (e *Engine) hasTable(name string) (bool, error) {
+	var count int
+	if err := e.db.QueryRow(HasTable, name).Scan(&count); err != nil {
+		return false, fmt.Errorf("error looking up table '%s'. %w", name, err)
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,46 @@
+package engine_test
+
+import (
+	"path"
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineStats(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	stats, err := engine.Stats()
+	require.NoError(t, err)
+
+	assert.Equal(t, TestDBPath, stats.DatabasePath)
+	assert.False(t, stats.InMemory)
+	assert.Equal(t, 75012, stats.Characters)
+	require.Contains(t, stats.Versions, congkit.CongkitV3)
+	require.Contains(t, stats.Versions, congkit.CongkitV5)
+	for _, versionStats := range stats.Versions {
+		assert.Greater(t, versionStats.Characters, 0)
+		assert.Greater(t, versionStats.Codes, 0)
+	}
+	assert.Positive(t, stats.SchemaVersion)
+	assert.False(t, stats.GeneratedAt.IsZero())
+	assert.Len(t, stats.TableRevision, 64)
+}
+
+func TestEngineStatsInMemory(t *testing.T) {
+	notExistDbPath := path.Join(t.TempDir(), "notexist.db")
+	engine := congkit.New(congkit.WithDatabase(notExistDbPath))
+	defer engine.Close()
+
+	stats, err := engine.Stats()
+	require.NoError(t, err)
+
+	assert.Equal(t, notExistDbPath, stats.DatabasePath)
+	assert.True(t, stats.InMemory)
+	assert.Zero(t, stats.Characters)
+	assert.Empty(t, stats.Versions)
+	assert.Zero(t, stats.SchemaVersion)
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"unicode/utf8"
+
+	"github.com/antonyho/go-congkit/userdict"
+)
+
+// WithUserDictionary merges the user dictionary with the database.
+// The texts mapped in the user dictionary are listed first and
+// the hidden texts are removed from the results.
+// The engine does not close the user dictionary.
+func WithUserDictionary(dict *userdict.Dictionary) Option {
+	return func(e *Engine) {
+		e.userDict = dict
+	}
+}
+
+// overlay merges the user dictionary into the characters of the code.
+// Only the single character texts of the user dictionary are merged.
+func (e *Engine) overlay(code string, results []rune) ([]rune, error) {
+	if e.userDict == nil {
+		return results, nil
+	}
+
+	texts, err := e.userTexts(code)
+	if err != nil {
+		return results, err
+	}
+
+	merged := make([]rune, 0, len(texts)+len(results))
+	seen := make(map[rune]bool, len(texts)+len(results))
+	for _, text := range texts {
+		if char, size := utf8.DecodeRuneInString(text); size == len(text) && !seen[char] {
+			merged = append(merged, char)
+			seen[char] = true
+		}
+	}
+	for _, char := range results {
+		if seen[char] {
+			continue
+		}
+		hidden, err := e.isHidden(string(char))
+		if err != nil {
+			return results, err
+		}
+		if !hidden {
+			merged = append(merged, char)
+			seen[char] = true
+		}
+	}
+
+	return merged, nil
+}
+
+// overlayTexts merges the user dictionary into the candidates of the code.
+func (e *Engine) overlayTexts(code string, candidates []string) ([]string, error) {
+	if e.userDict == nil {
+		return candidates, nil
+	}
+
+	texts, err := e.userTexts(code)
+	if err != nil {
+		return candidates, err
+	}
+
+	merged := make([]string, 0, len(texts)+len(candidates))
+	seen := make(map[string]bool, len(texts)+len(candidates))
+	// Texts mapped by the user are shown even if they are hidden.
+	for _, text := range texts {
+		if !seen[text] {
+			merged = append(merged, text)
+			seen[text] = true
+		}
+	}
+	for _, text := range candidates {
+		if seen[text] {
+			continue
+		}
+		hidden, err := e.isHidden(text)
+		if err != nil {
+			return candidates, err
+		}
+		if !hidden {
+			merged = append(merged, text)
+			seen[text] = true
+		}
+	}
+
+	return merged, nil
+}
+
+// userTexts looks up the texts of the code in the user dictionary.
+func (e *Engine) userTexts(code string) ([]string, error) {
+	texts, err := e.userDict.Lookup(code)
+	if err != nil {
+		e.logger.Error("user dictionary lookup failed", "radicals", code, "error", err)
+	}
+
+	return texts, err
+}
+
+// isHidden reports whether the text is hidden in the user dictionary.
+func (e *Engine) isHidden(text string) (bool, error) {
+	if e.userDict == nil {
+		return false, nil
+	}
+
+	hidden, err := e.userDict.IsHidden(text)
+	if err != nil {
+		e.logger.Error("user dictionary hidden texts lookup failed", "error", err)
+	}
+
+	return hidden, err
+}
@@ -0,0 +1,70 @@
+package engine_test
+
+import (
+	"path"
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/userdict"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineWithUserDictionary(t *testing.T) {
+	dict, err := userdict.Open(path.Join(t.TempDir(), "user.db"))
+	require.NoError(t, err)
+	defer dict.Close()
+	require.NoError(t, dict.Add("hqi", "倉"))
+	require.NoError(t, dict.Add("hqi", "我們公司"))
+	require.NoError(t, dict.Hide("牫"))
+
+	engine := congkit.New(congkit.WithDatabase(TestDBPath), congkit.WithUserDictionary(dict))
+	defer engine.Close()
+
+	results, err := engine.Encode("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, '倉', results[0], "user texts come first")
+	assert.ElementsMatch(t, []rune{'倉', '我', '𥫻'}, results)
+
+	candidates, err := engine.Candidates("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"倉", "我們公司"}, candidates[:2])
+	assert.ElementsMatch(t, []string{"倉", "我們公司", "我", "𥫻"}, candidates)
+
+	segments, err := engine.EncodeSequence("hqi")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []rune{'倉', '我', '𥫻'}, segments[0].Results)
+
+	fuzzy, err := engine.EncodeFuzzy("hqi")
+	require.NoError(t, err)
+	for _, candidate := range fuzzy {
+		assert.NotEqual(t, '牫', candidate.Char)
+	}
+
+	require.NoError(t, dict.Unhide("牫"))
+	results, err = engine.Encode("hqi")
+	require.NoError(t, err)
+	assert.Contains(t, results, '牫', "user dictionary changes apply at query time")
+}
+
+func TestEngineCandidatesMatchEncode(t *testing.T) {
+	dict, err := userdict.Open(path.Join(t.TempDir(), "user.db"))
+	require.NoError(t, err)
+	defer dict.Close()
+	require.NoError(t, dict.Add("hqi", "倉"))
+	require.NoError(t, dict.Hide("牫"))
+
+	engine := congkit.New(congkit.WithDatabase(TestDBPath), congkit.WithUserDictionary(dict), congkit.WithFuzzy())
+	defer engine.Close()
+
+	results, err := engine.Encode("hqi")
+	require.NoError(t, err)
+	candidates, err := engine.Candidates("hqi")
+	require.NoError(t, err)
+	expected := make([]string, len(results))
+	for i, char := range results {
+		expected[i] = string(char)
+	}
+	assert.Equal(t, expected, candidates, "the candidates are overlaid and ranked like the characters")
+	assert.NotContains(t, candidates, "牫")
+}
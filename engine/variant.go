@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNoUserDictionary is returned on remembering a preference without a user dictionary.
+var ErrNoUserDictionary = errors.New("engine: no user dictionary")
+
+// VariantGroup is a primary character and its variant characters.
+type VariantGroup struct {
+	Primary  rune
+	Variants []rune
+}
+
+// Variants lists the variant characters of the character in the database.
+// Databases generated before the variants were imported have no variants.
+func (e *Engine) Variants(char rune) (variants []rune, err error) {
+	if !e.tables["variants"] {
+		return []rune{}, nil
+	}
+
+	rows, err := e.db.Query(GetVariants, string(char))
+	if err != nil {
+		e.logger.Error("variant query failed", "char", string(char), "error", err)
+		return
+	}
+
+	return scanChars(rows)
+}
+
+// GroupVariants groups the characters which are variants of each other,
+// e.g. 產 and 産. The groups are in the order of their first characters.
+// The preferred variant in the user dictionary is the primary character of its group,
+// otherwise the first character of the group is.
+func (e *Engine) GroupVariants(chars []rune) (groups []VariantGroup, err error) {
+	// Each character points to an earlier character of its group, the first one points to itself.
+	group := make([]int, len(chars))
+	index := make(map[rune]int, len(chars))
+	for i, char := range chars {
+		group[i] = i
+		if _, ok := index[char]; !ok {
+			index[char] = i
+		}
+	}
+	first := func(i int) int {
+		for group[i] != i {
+			i = group[i]
+		}
+		return i
+	}
+
+	pairs, err := e.variantPairs(index)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range pairs {
+		if j, ok := index[pair[1]]; ok {
+			a, b := first(index[pair[0]]), first(j)
+			group[max(a, b)] = min(a, b)
+		}
+	}
+
+	preferred, err := e.preferredTexts()
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[int][]rune, len(chars))
+	order := make([]int, 0, len(chars))
+	for i, char := range chars {
+		if index[char] != i {
+			continue
+		}
+		f := first(i)
+		if _, ok := members[f]; !ok {
+			order = append(order, f)
+		}
+		members[f] = append(members[f], char)
+	}
+
+	groups = make([]VariantGroup, 0, len(order))
+	for _, f := range order {
+		primary := 0
+		for i, char := range members[f] {
+			if preferred[string(char)] {
+				primary = i
+				break
+			}
+		}
+		variants := make([]rune, 0, len(members[f])-1)
+		variants = append(variants, members[f][:primary]...)
+		variants = append(variants, members[f][primary+1:]...)
+		groups = append(groups, VariantGroup{Primary: members[f][primary], Variants: variants})
+	}
+
+	return
+}
+
+// variantPairs lists the characters and their variants of the distinct characters
+// in a single query. Databases without the variants have no pairs.
+func (e *Engine) variantPairs(chars map[rune]int) (pairs [][2]rune, err error) {
+	if !e.tables["variants"] || len(chars) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, len(chars))
+	for char := range chars {
+		args = append(args, string(char))
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	rows, err := e.db.Query(fmt.Sprintf(GetVariantsOfChars, placeholders), args...)
+	if err != nil {
+		e.logger.Error("variant query failed", "chars", len(chars), "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var char, variant string
+		if scanErr := rows.Scan(&char, &variant); scanErr != nil {
+			err = errors.Join(scanErr, err)
+			continue
+		}
+		c, _ := utf8.DecodeRuneInString(char)
+		v, _ := utf8.DecodeRuneInString(variant)
+		pairs = append(pairs, [2]rune{c, v})
+	}
+	err = errors.Join(rows.Err(), err)
+
+	return pairs, err
+}
+
+// EncodeVariants lists the characters matching the radicals like Encode,
+// with the variant characters grouped by GroupVariants.
+func (e *Engine) EncodeVariants(radicals string) ([]VariantGroup, error) {
+	results, err := e.Encode(radicals)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.GroupVariants(results)
+}
+
+// PreferVariant remembers the character as the preferred one of its variants
+// in the user dictionary. ErrNoUserDictionary is returned without a user dictionary.
+func (e *Engine) PreferVariant(char rune) error {
+	if e.userDict == nil {
+		return ErrNoUserDictionary
+	}
+
+	variants, err := e.Variants(char)
+	if err != nil {
+		return err
+	}
+	others := make([]string, len(variants))
+	for i, variant := range variants {
+		others[i] = string(variant)
+	}
+	if err := e.userDict.Prefer(string(char), others...); err != nil {
+		return fmt.Errorf("error preferring variant '%c'. %w", char, err)
+	}
+
+	return nil
+}
+
+// preferredTexts looks up the preferred single character texts in the user dictionary.
+func (e *Engine) preferredTexts() (map[string]bool, error) {
+	if e.userDict == nil {
+		return nil, nil
+	}
+
+	texts, err := e.userDict.Preferred()
+	if err != nil {
+		e.logger.Error("user dictionary preferred texts lookup failed", "error", err)
+		return nil, err
+	}
+	preferred := make(map[string]bool, len(texts))
+	for _, text := range texts {
+		if utf8.RuneCountInString(text) == 1 {
+			preferred[text] = true
+		}
+	}
+
+	return preferred, nil
+}
@@ -0,0 +1,87 @@
+package engine_test
+
+import (
+	"path"
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/userdict"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineVariants(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	variants, err := engine.Variants('戶')
+	require.NoError(t, err)
+	assert.Equal(t, []rune{'户', '戸'}, variants)
+
+	variants, err = engine.Variants('我')
+	require.NoError(t, err)
+	assert.Empty(t, variants)
+}
+
+func TestEngineEncodeVariants(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath), congkit.WithCongkitV3())
+	defer engine.Close()
+
+	groups, err := engine.EncodeVariants("yhhqm")
+	require.NoError(t, err)
+	assert.Equal(t, []congkit.VariantGroup{{Primary: '產', Variants: []rune{'産'}}}, groups)
+}
+
+func TestEngineGroupVariants(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	groups, err := engine.GroupVariants([]rune{'我', '户', '產', '戶', '我', '戸'})
+	require.NoError(t, err)
+	assert.Equal(t, []congkit.VariantGroup{
+		{Primary: '我', Variants: []rune{}},
+		{Primary: '户', Variants: []rune{'戶', '戸'}},
+		{Primary: '產', Variants: []rune{}},
+	}, groups)
+
+	groups, err = engine.GroupVariants(nil)
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+}
+
+func TestEnginePreferVariant(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath), congkit.WithCongkitV3())
+	defer engine.Close()
+	assert.ErrorIs(t, engine.PreferVariant('産'), congkit.ErrNoUserDictionary)
+
+	dict, err := userdict.Open(path.Join(t.TempDir(), "user.db"))
+	require.NoError(t, err)
+	defer dict.Close()
+	engine.Set(congkit.WithUserDictionary(dict))
+
+	require.NoError(t, engine.PreferVariant('産'))
+	groups, err := engine.EncodeVariants("yhhqm")
+	require.NoError(t, err)
+	assert.Equal(t, []congkit.VariantGroup{{Primary: '産', Variants: []rune{'產'}}}, groups)
+
+	require.NoError(t, engine.PreferVariant('產'))
+	groups, err = engine.EncodeVariants("yhhqm")
+	require.NoError(t, err)
+	assert.Equal(t, []congkit.VariantGroup{{Primary: '產', Variants: []rune{'産'}}}, groups)
+	preferred, err := dict.Preferred()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"產"}, preferred)
+}
+
+func TestEngineVariantsWithoutTable(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(path.Join(t.TempDir(), "notexist.db")))
+	defer engine.Close()
+
+	variants, err := engine.Variants('產')
+	require.NoError(t, err)
+	assert.Empty(t, variants)
+
+	groups, err := engine.GroupVariants([]rune{'產', '産'})
+	require.NoError(t, err)
+	assert.Len(t, groups, 2)
+}
@@ -0,0 +1,26 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// hasWildcard reports whether the code has any radical.Wildcard.
+func hasWildcard(code string) bool {
+	return strings.ContainsRune(code, radical.Wildcard)
+}
+
+// queryOf returns the query for the radicals.
+// Radicals with wildcards are matched by the LIKE pattern of the prediction query,
+// which has no trailing '%' without the prediction.
+func (e *Engine) queryOf(radicals string) string {
+	if !hasWildcard(radicals) || e.Easy || e.Prediction {
+		return e.query
+	}
+	if e.OutputSimplified {
+		return GetSimplifiedCharWithPrediction
+	}
+
+	return GetCharWithPrediction
+}
@@ -0,0 +1,71 @@
+package engine_test
+
+import (
+	"testing"
+
+	congkit "github.com/antonyho/go-congkit/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineEncodeWildcard(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	results, err := engine.Encode("h*i")
+	require.NoError(t, err)
+	assert.Subset(t, results, []rune{'我', '牫', '𥫻'})
+
+	results, err = engine.Encode("oi*r")
+	require.NoError(t, err)
+	assert.Contains(t, results, '倉')
+
+	results, err = engine.Encode("人＊日口")
+	require.NoError(t, err)
+	assert.Contains(t, results, '倉', "the wildcard glyph is a wildcard")
+
+	results, err = engine.Encode("oia**")
+	require.NoError(t, err)
+	assert.NotContains(t, results, '倉', "a wildcard matches one radical")
+}
+
+func TestEngineEncodeWildcardLikeCharacters(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	for _, code := range []string{"h*%", "h*_", "%*", "_*"} {
+		results, err := engine.Encode(code)
+		require.NoError(t, err)
+		assert.Empty(t, results, "'%s' has no LIKE wildcards", code)
+	}
+
+	segments, err := engine.EncodeSequence("h*% hqi")
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+	assert.Empty(t, segments[0].Results)
+
+	engine.Set(congkit.WithPrediction())
+	results, err := engine.Encode("h%")
+	require.NoError(t, err)
+	assert.Empty(t, results, "the predicted code is escaped")
+}
+
+func TestEngineEncodeSequenceWildcard(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath))
+	defer engine.Close()
+
+	segments, err := engine.EncodeSequence("oi*r hqi")
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+	assert.Contains(t, segments[0].Results, '倉')
+	assert.ElementsMatch(t, []rune{'我', '牫', '𥫻'}, segments[1].Results)
+}
+
+func TestEngineEncodeWildcardSimplified(t *testing.T) {
+	engine := congkit.New(congkit.WithDatabase(TestDBPath), congkit.WithSimplified())
+	defer engine.Close()
+
+	results, err := engine.Encode("oi*r")
+	require.NoError(t, err)
+	assert.Contains(t, results, '仓')
+}
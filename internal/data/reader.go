@@ -6,7 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Errors on parsing raw data from embed data file
@@ -16,13 +19,23 @@ var (
 	ErrEmptyLine    = errors.New("data: empty line")
 )
 
+// Entry is the fields of a line in a data file.
+type Entry struct {
+	Line   int      // Line number in the data file, starting from 1
+	Fields []string // Fields of the line
+}
+
 //go:embed assets/table.txt
 var builtinCongkitTable embed.FS
 
-func ReadTable(congkitTableContent fs.File) ([][]string, error) {
-	table := make([][]string, 0)
+// VariantKinds are the kinds of the Unihan variants used for grouping the variant characters.
+// The other kinds, like the simplified variants, are skipped.
+var VariantKinds = []string{"kZVariant", "kSemanticVariant"}
+
+func ReadTable(congkitTableContent fs.File) ([]Entry, error) {
+	table := make([]Entry, 0)
 	scanner := bufio.NewScanner(congkitTableContent)
-	for lineNum := 0; scanner.Scan(); lineNum++ {
+	for lineNum := 1; scanner.Scan(); lineNum++ {
 		entry, err := readRaw(scanner.Text())
 		if err != nil {
 			switch err {
@@ -32,13 +45,13 @@ func ReadTable(congkitTableContent fs.File) ([][]string, error) {
 				return nil, fmt.Errorf("line %d: %w", lineNum, err)
 			}
 		}
-		table = append(table, entry)
+		table = append(table, Entry{Line: lineNum, Fields: entry})
 	}
 
 	return table, nil
 }
 
-func ReadBuiltinTable() ([][]string, error) {
+func ReadBuiltinTable() ([]Entry, error) {
 	file, err := builtinCongkitTable.Open("assets/table.txt")
 	if err != nil {
 		return nil, err
@@ -64,3 +77,116 @@ func readRaw(line string) ([]string, error) {
 
 	return fields, nil
 }
+
+// ReadPhrases reads the phrase list of lines with a phrase and its frequency.
+func ReadPhrases(phrasesContent fs.File) ([]Entry, error) {
+	phrases := make([]Entry, 0)
+	scanner := bufio.NewScanner(phrasesContent)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		entry, err := readPhraseRaw(scanner.Text())
+		if err != nil {
+			switch err {
+			case ErrCommentLine, ErrEmptyLine:
+				continue
+			default:
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+		}
+		phrases = append(phrases, Entry{Line: lineNum, Fields: entry})
+	}
+
+	return phrases, nil
+}
+
+// Read the line of a phrase and its frequency from the phrase list.
+func readPhraseRaw(line string) ([]string, error) {
+	trimmedLine := strings.TrimSpace(line)
+	if len(trimmedLine) == 0 {
+		return nil, ErrEmptyLine
+	}
+	if trimmedLine[0] == '#' {
+		return nil, ErrCommentLine
+	}
+
+	fields := strings.Fields(trimmedLine)
+	if len(fields) != 2 {
+		return nil, ErrMalformEntry
+	}
+
+	return fields, nil
+}
+
+// ReadVariants reads the variants in the format of Unihan_Variants.txt.
+// Each entry is a character, the kind of the variant and the variant character.
+// A line with many variants gives an entry of each variant.
+// Variants of the kinds not in VariantKinds are skipped.
+func ReadVariants(variantsContent fs.File) ([][]string, error) {
+	variants := make([][]string, 0)
+	scanner := bufio.NewScanner(variantsContent)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		entries, err := readVariantRaw(scanner.Text())
+		if err != nil {
+			switch err {
+			case ErrCommentLine, ErrEmptyLine:
+				continue
+			default:
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+		}
+		variants = append(variants, entries...)
+	}
+
+	return variants, nil
+}
+
+// Read the line of a character, the kind of the variants and the variants,
+// e.g. "U+7522	kZVariant	U+7523<kHanYu:TZ".
+func readVariantRaw(line string) ([][]string, error) {
+	trimmedLine := strings.TrimSpace(line)
+	if len(trimmedLine) == 0 {
+		return nil, ErrEmptyLine
+	}
+	if trimmedLine[0] == '#' {
+		return nil, ErrCommentLine
+	}
+
+	fields := strings.Fields(trimmedLine)
+	if len(fields) < 3 {
+		return nil, ErrMalformEntry
+	}
+	char, err := parseCodePoint(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	kind := fields[1]
+	if !slices.Contains(VariantKinds, kind) {
+		return nil, nil
+	}
+
+	entries := make([][]string, 0, len(fields)-2)
+	for _, field := range fields[2:] {
+		// The sources of the variant follow the code point after '<'.
+		codePoint, _, _ := strings.Cut(field, "<")
+		variant, err := parseCodePoint(codePoint)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, []string{char, kind, variant})
+	}
+
+	return entries, nil
+}
+
+// parseCodePoint converts the code point in the form of "U+7522" to the character.
+func parseCodePoint(codePoint string) (string, error) {
+	hex, found := strings.CutPrefix(codePoint, "U+")
+	if !found {
+		return "", ErrMalformEntry
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || value > unicode.MaxRune {
+		return "", ErrMalformEntry
+	}
+
+	return string(rune(value)), nil
+}
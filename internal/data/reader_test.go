@@ -3,6 +3,7 @@ package data_test
 import (
 	"embed"
 	"testing"
+	"testing/fstest"
 
 	"github.com/antonyho/go-congkit/internal/data"
 	"github.com/stretchr/testify/assert"
@@ -12,6 +13,12 @@ import (
 //go:embed testdata/table.txt
 var testdataCongkitTable embed.FS
 
+//go:embed testdata/phrases.txt
+var testdataPhrases embed.FS
+
+//go:embed testdata/variants.txt
+var testdataVariants embed.FS
+
 func TestReadTable(t *testing.T) {
 	const expectedNumOfEntry = 5
 
@@ -22,6 +29,8 @@ func TestReadTable(t *testing.T) {
 	assert.Len(t, congkitTable, expectedNumOfEntry,
 		"result table size '%d' not as expected '%d'",
 		len(congkitTable), expectedNumOfEntry)
+	assert.Equal(t, 3, congkitTable[0].Line, "comment and empty lines are counted")
+	assert.Equal(t, "倉", congkitTable[0].Fields[0])
 }
 
 func TestReadBuiltinTable(t *testing.T) {
@@ -33,3 +42,50 @@ func TestReadBuiltinTable(t *testing.T) {
 		"result table size '%d' not as expected '%d'",
 		len(congkitTable), expectedNumOfEntry)
 }
+
+func TestReadPhrases(t *testing.T) {
+	testPhrases, err := testdataPhrases.Open("testdata/phrases.txt")
+	require.NoError(t, err, "failed loading test data")
+	phrases, err := data.ReadPhrases(testPhrases)
+	require.NoError(t, err, "failed parsing phrase data")
+	assert.Equal(t, []data.Entry{
+		{Line: 3, Fields: []string{"香港", "9800"}},
+		{Line: 4, Fields: []string{"香味", "3200"}},
+		{Line: 7, Fields: []string{"香蕉", "2900"}},
+	}, phrases, "entries keep their line numbers")
+}
+
+func TestReadMalformedPhrases(t *testing.T) {
+	malformed, err := fstest.MapFS{
+		"phrases.txt": {Data: []byte("香港 9800 1\n")},
+	}.Open("phrases.txt")
+	require.NoError(t, err)
+	phrases, err := data.ReadPhrases(malformed)
+	assert.ErrorIs(t, err, data.ErrMalformEntry)
+	assert.Nil(t, phrases)
+}
+
+func TestReadVariants(t *testing.T) {
+	testVariants, err := testdataVariants.Open("testdata/variants.txt")
+	require.NoError(t, err, "failed loading test data")
+	variants, err := data.ReadVariants(testVariants)
+	require.NoError(t, err, "failed parsing variant data")
+	assert.Equal(t, [][]string{
+		{"產", "kZVariant", "産"},
+		{"産", "kZVariant", "產"},
+		{"戶", "kZVariant", "户"},
+		{"戶", "kZVariant", "戸"},
+	}, variants, "simplified variants are skipped")
+}
+
+func TestReadMalformedVariants(t *testing.T) {
+	for _, line := range []string{"U+7522 kZVariant\n", "7522 kZVariant U+7523\n", "U+7522 kZVariant U+XYZ\n"} {
+		malformed, err := fstest.MapFS{
+			"variants.txt": {Data: []byte(line)},
+		}.Open("variants.txt")
+		require.NoError(t, err)
+		variants, err := data.ReadVariants(malformed)
+		assert.ErrorIs(t, err, data.ErrMalformEntry, line)
+		assert.Nil(t, variants)
+	}
+}
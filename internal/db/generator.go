@@ -1,16 +1,20 @@
 package db
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/antonyho/go-congkit/db/models"
+	"github.com/antonyho/go-congkit/internal/data"
 	// SQLite3 driver for the engine, the engine uses SQlite3.
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -44,6 +48,53 @@ const (
 
 	CreateRadicalsIndexQuery = `CREATE INDEX idx_radicals on radicals(version, radical);`
 
+	CreateCharsIndexQuery = `CREATE INDEX idx_characters on characters(tc);`
+
+	CreateShortCodesTableQuery = `
+	CREATE TABLE shortcodes (
+		char_idx INTEGER NOT NULL,
+		code TEXT NOT NULL,
+		priority INTEGER NOT NULL,
+		FOREIGN KEY(char_idx) REFERENCES characters(idx)
+	);
+	`
+
+	CreateShortCodesIndexQuery = `CREATE INDEX idx_shortcodes on shortcodes(code);`
+
+	CreatePhrasesTableQuery = `
+	CREATE TABLE phrases (
+		phrase TEXT NOT NULL PRIMARY KEY,
+		frequency INTEGER NOT NULL
+	);
+	`
+
+	CreatePhraseCodesTableQuery = `
+	CREATE TABLE phrase_codes (
+		phrase TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		code TEXT NOT NULL,
+		FOREIGN KEY(phrase) REFERENCES phrases(phrase)
+	);
+	`
+
+	CreatePhraseCodesIndexQuery = `CREATE INDEX idx_phrase_codes on phrase_codes(version, code);`
+
+	CreateVariantsTableQuery = `
+	CREATE TABLE variants (
+		char TEXT NOT NULL,
+		variant TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		PRIMARY KEY (char, variant)
+	);
+	`
+
+	CreateMetadataTableQuery = `
+	CREATE TABLE metadata (
+		key TEXT NOT NULL PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	`
+
 	AddCharsQuery = `
 	INSERT INTO characters (
 		idx, tc, sc, chinese, big5, hkcsc, zhuyin, kanji, 
@@ -56,13 +107,91 @@ const (
 	INSERT INTO radicals (char_idx, version, radical) 
 	VALUES (?, ?, ?);
 	`
+
+	AddShortCodesQuery = `
+	INSERT INTO shortcodes (char_idx, code, priority) 
+	VALUES (?, ?, ?);
+	`
+
+	AddPhrasesQuery = `
+	INSERT INTO phrases (phrase, frequency) 
+	VALUES (?, ?) 
+	ON CONFLICT (phrase) DO UPDATE SET frequency = MAX(frequency, excluded.frequency);
+	`
+
+	AddPhraseCodesQuery = `
+	INSERT INTO phrase_codes (phrase, version, code) 
+	VALUES (?, ?, ?);
+	`
+
+	AddVariantsQuery = `
+	INSERT OR IGNORE INTO variants (char, variant, kind) 
+	VALUES (?, ?, ?);
+	`
+
+	AddMetadataQuery = `
+	INSERT INTO metadata (key, value) 
+	VALUES (?, ?);
+	`
 )
 
+// SchemaVersion is the version of the database schema created by Generate.
+const SchemaVersion = 5
+
+// MaxPhraseCodes limits the codes of a phrase in a Congkit version,
+// as the characters having multiple codes multiply the codes of the phrase.
+const MaxPhraseCodes = 16
+
+// MaxPhraseCodeLength is the length of the longest phrase code,
+// as the input session takes at most 5 radicals like a character code.
+const MaxPhraseCodeLength = 5
+
+// Option configures the database generation.
+type Option func(*generator)
+
+// WithLogger sets the logger for the generation events.
+// The default logger is slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(g *generator) {
+		g.logger = logger
+	}
+}
+
+// WithPhrases adds the phrases of the phrase list into the database.
+// Each entry is a phrase and its frequency.
+func WithPhrases(phrases []data.Entry) Option {
+	return func(g *generator) {
+		g.phrases = phrases
+	}
+}
+
+// WithVariants adds the variant characters into the database.
+// Each entry is a character, the kind of the variant and the variant character.
+// The variants of the characters without code are skipped.
+func WithVariants(variants [][]string) Option {
+	return func(g *generator) {
+		g.variants = variants
+	}
+}
+
+type generator struct {
+	logger   *slog.Logger
+	phrases  []data.Entry
+	variants [][]string
+}
+
 // Generate SQLite3 database file from raw data
-func Generate(raw [][]string, dbFilePath string) error {
+// The warnings on converting the entries are logged with their line numbers.
+func Generate(raw []data.Entry, dbFilePath string, options ...Option) error {
+	g := &generator{
+		logger: slog.Default(),
+	}
+	for _, option := range options {
+		option(g)
+	}
+
 	if _, err := os.Stat(dbFilePath); !errors.Is(err, os.ErrNotExist) {
-		log.Println("File already exists at ", dbFilePath)
-		log.Println("Remove existing file")
+		g.logger.Info("removing existing db file", "path", dbFilePath)
 		if err := os.Remove(dbFilePath); err != nil {
 			return fmt.Errorf("failed to remove existing db file. %w", err)
 		}
@@ -77,12 +206,36 @@ func Generate(raw [][]string, dbFilePath string) error {
 	if _, err := db.Exec(CreateCharsTableQuery); err != nil {
 		return fmt.Errorf("error creating 'characters' table. %w", err)
 	}
+	if _, err := db.Exec(CreateCharsIndexQuery); err != nil {
+		return fmt.Errorf("error creating index for 'characters' table. %w", err)
+	}
 	if _, err := db.Exec(CreateRadicalsTableQuery); err != nil {
 		return fmt.Errorf("error creating 'radicals' table. %w", err)
 	}
 	if _, err := db.Exec(CreateRadicalsIndexQuery); err != nil {
 		return fmt.Errorf("error creating index for 'radicals' table. %w", err)
 	}
+	if _, err := db.Exec(CreateShortCodesTableQuery); err != nil {
+		return fmt.Errorf("error creating 'shortcodes' table. %w", err)
+	}
+	if _, err := db.Exec(CreateShortCodesIndexQuery); err != nil {
+		return fmt.Errorf("error creating index for 'shortcodes' table. %w", err)
+	}
+	if _, err := db.Exec(CreatePhrasesTableQuery); err != nil {
+		return fmt.Errorf("error creating 'phrases' table. %w", err)
+	}
+	if _, err := db.Exec(CreatePhraseCodesTableQuery); err != nil {
+		return fmt.Errorf("error creating 'phrase_codes' table. %w", err)
+	}
+	if _, err := db.Exec(CreatePhraseCodesIndexQuery); err != nil {
+		return fmt.Errorf("error creating index for 'phrase_codes' table. %w", err)
+	}
+	if _, err := db.Exec(CreateVariantsTableQuery); err != nil {
+		return fmt.Errorf("error creating 'variants' table. %w", err)
+	}
+	if _, err := db.Exec(CreateMetadataTableQuery); err != nil {
+		return fmt.Errorf("error creating 'metadata' table. %w", err)
+	}
 
 	tx, err := db.Begin()
 	if err != nil {
@@ -98,9 +251,14 @@ func Generate(raw [][]string, dbFilePath string) error {
 		return fmt.Errorf("error preparing insert into 'radicals' table statement. %w", err)
 	}
 	defer addRadicalStmt.Close()
+	addShortCodeStmt, err := tx.Prepare(AddShortCodesQuery)
+	if err != nil {
+		return fmt.Errorf("error preparing insert into 'shortcodes' table statement. %w", err)
+	}
+	defer addShortCodeStmt.Close()
 
-	for rowNum, row := range raw {
-		char, radicalSets := convert(rowNum, row)
+	for rowNum, entry := range raw {
+		char, radicalSets, shortCode := g.convert(rowNum, entry)
 		if _, err := addCharStmt.Exec(
 			char.Idx,
 			string(char.Tradition),
@@ -127,57 +285,124 @@ func Generate(raw [][]string, dbFilePath string) error {
 					char.Tradition, radicalSet.Radical, err)
 			}
 		}
+		if shortCode != nil {
+			if _, err := addShortCodeStmt.Exec(
+				shortCode.CharIdx,
+				shortCode.Code,
+				shortCode.Priority,
+			); err != nil {
+				return fmt.Errorf("error inserting '%c' short code '%s' into 'shortcodes' table. %w",
+					char.Tradition, shortCode.Code, err)
+			}
+		}
+	}
+
+	addPhraseStmt, err := tx.Prepare(AddPhrasesQuery)
+	if err != nil {
+		return fmt.Errorf("error preparing insert into 'phrases' table statement. %w", err)
+	}
+	defer addPhraseStmt.Close()
+	addPhraseCodeStmt, err := tx.Prepare(AddPhraseCodesQuery)
+	if err != nil {
+		return fmt.Errorf("error preparing insert into 'phrase_codes' table statement. %w", err)
+	}
+	defer addPhraseCodeStmt.Close()
+	charCodes := charCodes(raw)
+	addedPhrases := make(map[string]bool, len(g.phrases))
+	for _, entry := range g.phrases {
+		phrase := g.convertPhrase(entry)
+		if _, err := addPhraseStmt.Exec(phrase.Phrase, phrase.Frequency); err != nil {
+			return fmt.Errorf("error inserting '%s' into 'phrases' table. %w", phrase.Phrase, err)
+		}
+		if addedPhrases[phrase.Phrase] {
+			continue
+		}
+		addedPhrases[phrase.Phrase] = true
+		for _, phraseCode := range g.phraseCodes(phrase.Phrase, charCodes) {
+			if _, err := addPhraseCodeStmt.Exec(
+				phraseCode.Phrase,
+				phraseCode.Version,
+				phraseCode.Code,
+			); err != nil {
+				return fmt.Errorf("error inserting '%s' code '%s' into 'phrase_codes' table. %w",
+					phraseCode.Phrase, phraseCode.Code, err)
+			}
+		}
+	}
+
+	addVariantStmt, err := tx.Prepare(AddVariantsQuery)
+	if err != nil {
+		return fmt.Errorf("error preparing insert into 'variants' table statement. %w", err)
+	}
+	defer addVariantStmt.Close()
+	for _, variant := range g.convertVariants(charCodes) {
+		if _, err := addVariantStmt.Exec(
+			string(variant.Char),
+			string(variant.Variant),
+			variant.Kind,
+		); err != nil {
+			return fmt.Errorf("error inserting '%c' variant '%c' into 'variants' table. %w",
+				variant.Char, variant.Variant, err)
+		}
+	}
+
+	for _, metadata := range g.metadata(raw) {
+		if _, err := tx.Exec(AddMetadataQuery, metadata.Key, metadata.Value); err != nil {
+			return fmt.Errorf("error inserting '%s' into 'metadata' table. %w", metadata.Key, err)
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("error committing inserted transactions to db. %w", err)
 	}
+	g.logger.Info("db file generated", "path", dbFilePath,
+		"characters", len(raw), "phrases", len(g.phrases), "variants", len(g.variants))
 
 	return nil
 }
 
-func convert(idx int, row []string) (models.Character, []models.RadicalSet) {
+// metadata describes the generated database.
+// The table revision is the SHA-256 digest of the raw data.
+func (g *generator) metadata(raw []data.Entry) []models.Metadata {
+	digest := sha256.New()
+	for _, entry := range raw {
+		digest.Write([]byte(strings.Join(entry.Fields, " ")))
+		digest.Write([]byte{'\n'})
+	}
+
+	return []models.Metadata{
+		{Key: models.MetadataSchemaVersion, Value: strconv.Itoa(SchemaVersion)},
+		{Key: models.MetadataGeneratedAt, Value: time.Now().UTC().Format(time.RFC3339)},
+		{Key: models.MetadataTableRevision, Value: hex.EncodeToString(digest.Sum(nil))},
+	}
+}
+
+// convert the entry of raw data into a character, its radical sets and its short code.
+// Fields which are not integers are logged and taken as 0.
+func (g *generator) convert(idx int, entry data.Entry) (models.Character, []models.RadicalSet, *models.ShortCode) {
+	row := entry.Fields
 	tc, _ := utf8.DecodeRuneInString(row[0])
 	var sc rune
 	if row[1] != "NA" {
 		sc, _ = utf8.DecodeRuneInString(row[1])
 	}
-	chinese, err := strconv.Atoi(row[2])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 3 to int", tc)
-	}
-	big5, err := strconv.Atoi(row[3])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 4 to int", tc)
-	}
-	hkcsc, err := strconv.Atoi(row[4])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 5 to int", tc)
-	}
-	zhuyin, err := strconv.Atoi(row[5])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 6 to int", tc)
-	}
-	kanji, err := strconv.Atoi(row[6])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 7 to int", tc)
-	}
-	hiragana, err := strconv.Atoi(row[7])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 8 to int", tc)
-	}
-	katakana, err := strconv.Atoi(row[8])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 9 to int", tc)
-	}
-	punctuationMark, err := strconv.Atoi(row[9])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 10 to int", tc)
-	}
-	miscSymbol, err := strconv.Atoi(row[10])
-	if err != nil {
-		log.Printf("Unable to convert rune '%c' column 11 to int", tc)
+	atoi := func(column int) int {
+		value, err := strconv.Atoi(row[column-1])
+		if err != nil {
+			g.logger.Warn("unable to convert column to int",
+				"line", entry.Line, "char", string(tc), "column", column, "value", row[column-1], "error", err)
+		}
+		return value
 	}
+	chinese := atoi(3)
+	big5 := atoi(4)
+	hkcsc := atoi(5)
+	zhuyin := atoi(6)
+	kanji := atoi(7)
+	hiragana := atoi(8)
+	katakana := atoi(9)
+	punctuationMark := atoi(10)
+	miscSymbol := atoi(11)
 
 	char := models.Character{
 		Idx:             idx,
@@ -218,20 +443,133 @@ func convert(idx int, row []string) (models.Character, []models.RadicalSet) {
 			radicalSets = append(radicalSets, v5radical)
 		}
 	}
+
+	var shortCode *models.ShortCode
 	if row[13] != "NA" && row[13] != "SPACE" {
-		v3radical := models.RadicalSet{
-			CharIdx: idx,
-			Version: 3,
-			Radical: row[13],
+		shortCode = &models.ShortCode{
+			CharIdx:  idx,
+			Code:     row[13],
+			Priority: atoi(15),
+		}
+	}
+
+	return char, radicalSets, shortCode
+}
+
+// convertPhrase converts the entry of a phrase and its frequency.
+// Frequency which is not an integer is logged and taken as 0.
+func (g *generator) convertPhrase(entry data.Entry) models.Phrase {
+	row := entry.Fields
+	frequency, err := strconv.Atoi(row[1])
+	if err != nil {
+		g.logger.Warn("unable to convert phrase frequency to int",
+			"line", entry.Line, "phrase", row[0], "value", row[1], "error", err)
+	}
+
+	return models.Phrase{
+		Phrase:    row[0],
+		Frequency: frequency,
+	}
+}
+
+// convertVariants converts the variant entries of the characters having code.
+// Variants are added in both directions, as the source may only list one of them.
+func (g *generator) convertVariants(charCodes map[rune]map[int][]string) []models.Variant {
+	variants := make([]models.Variant, 0, len(g.variants)*2)
+	for _, row := range g.variants {
+		char, _ := utf8.DecodeRuneInString(row[0])
+		variant, _ := utf8.DecodeRuneInString(row[2])
+		if char == variant || charCodes[char] == nil || charCodes[variant] == nil {
+			continue
 		}
-		radicalSets = append(radicalSets, v3radical)
-		v5radical := models.RadicalSet{
-			CharIdx: idx,
-			Version: 5,
-			Radical: row[13],
+		variants = append(variants,
+			models.Variant{Char: char, Variant: variant, Kind: row[1]},
+			models.Variant{Char: variant, Variant: char, Kind: row[1]},
+		)
+	}
+
+	return variants
+}
+
+// charCodes maps the characters of the raw data to their codes of each Congkit version.
+func charCodes(raw []data.Entry) map[rune]map[int][]string {
+	codes := make(map[rune]map[int][]string, len(raw))
+	for _, entry := range raw {
+		row := entry.Fields
+		tc, _ := utf8.DecodeRuneInString(row[0])
+		for version, field := range map[int]string{3: row[11], 5: row[12]} {
+			if field == "NA" {
+				continue
+			}
+			if codes[tc] == nil {
+				codes[tc] = make(map[int][]string)
+			}
+			codes[tc][version] = append(codes[tc][version], strings.Split(field, ",")...)
+		}
+	}
+
+	return codes
+}
+
+// phraseCodes lists the codes of the phrase in each Congkit version.
+// A phrase code is either the first radical of each character, or the first
+// and the last radical of each character. Codes longer than MaxPhraseCodeLength
+// cannot be typed and are skipped. Phrases with a character without code are
+// logged and skipped.
+func (g *generator) phraseCodes(phrase string, charCodes map[rune]map[int][]string) []models.PhraseCode {
+	phraseCodes := make([]models.PhraseCode, 0)
+	for _, version := range []int{3, 5} {
+		firsts := []string{""}
+		quicks := []string{""}
+		for _, char := range phrase {
+			codes := charCodes[char][version]
+			if len(codes) == 0 {
+				g.logger.Warn("phrase character has no code",
+					"phrase", phrase, "char", string(char), "version", version)
+				firsts, quicks = nil, nil
+				break
+			}
+			firsts = combineCodes(firsts, codes, func(code string) string {
+				return code[:1]
+			})
+			quicks = combineCodes(quicks, codes, func(code string) string {
+				if len(code) == 1 {
+					return code
+				}
+				return code[:1] + code[len(code)-1:]
+			})
+		}
+
+		seen := make(map[string]bool)
+		for _, code := range append(firsts, quicks...) {
+			if !seen[code] && len(code) <= MaxPhraseCodeLength {
+				seen[code] = true
+				phraseCodes = append(phraseCodes, models.PhraseCode{
+					Phrase:  phrase,
+					Version: version,
+					Code:    code,
+				})
+			}
+		}
+	}
+
+	return phraseCodes
+}
+
+// combineCodes appends the part of each character code to each prefix,
+// up to MaxPhraseCodes combinations.
+func combineCodes(prefixes []string, codes []string, part func(string) string) []string {
+	combined := make([]string, 0, len(prefixes))
+	seen := make(map[string]bool)
+	for _, prefix := range prefixes {
+		for _, code := range codes {
+			combination := prefix + part(code)
+			if !seen[combination] && len(combined) < MaxPhraseCodes {
+				seen[combination] = true
+				combined = append(combined, combination)
+			}
 		}
-		radicalSets = append(radicalSets, v5radical)
 	}
 
-	return char, radicalSets
+	return combined
 }
@@ -1,12 +1,18 @@
 package db_test
 
 import (
+	"bytes"
 	"database/sql"
 	"embed"
+	"log/slog"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/antonyho/go-congkit/db/models"
 	"github.com/antonyho/go-congkit/internal/data"
 	"github.com/antonyho/go-congkit/internal/db"
 	_ "github.com/mattn/go-sqlite3"
@@ -18,6 +24,18 @@ const (
 	CountCharsQuery = `SELECT COUNT(ALL) FROM characters;`
 
 	CountRadicalsQuery = `SELECT COUNT(ALL) FROM radicals;`
+
+	CountShortCodesQuery = `SELECT COUNT(ALL) FROM shortcodes;`
+
+	GetPhraseFrequencyQuery = `SELECT frequency FROM phrases WHERE phrase = ?;`
+
+	SelectPhraseCodesQuery = `SELECT version || ':' || code FROM phrase_codes WHERE phrase = ?;`
+
+	CountPhraseCodesQuery = `SELECT COUNT(ALL) FROM phrase_codes;`
+
+	SelectVariantsQuery = `SELECT char || variant || ':' || kind FROM variants;`
+
+	SelectMetadataQuery = `SELECT key, value FROM metadata;`
 )
 
 //go:embed testdata/table.txt
@@ -27,7 +45,14 @@ func TestGenerate(t *testing.T) {
 	congkitTable := loadTestTableData(t)
 
 	tempDbFile := path.Join(t.TempDir(), "test.db")
-	err := db.Generate(congkitTable, tempDbFile)
+	phrases := []data.Entry{
+		{Line: 1, Fields: []string{"香港", "9800"}},
+		{Line: 2, Fields: []string{"香味", "3200"}},
+		{Line: 3, Fields: []string{"香港", "100"}},
+		{Line: 4, Fields: []string{"倉頡", "2200"}},
+		{Line: 5, Fields: []string{"倉頡倉", "100"}},
+	}
+	err := db.Generate(congkitTable, tempDbFile, db.WithPhrases(phrases))
 	require.NoError(t, err, "failed generating database")
 
 	assert.FileExists(t, tempDbFile, "db file was not created")
@@ -37,6 +62,7 @@ func TestGenerate(t *testing.T) {
 
 	assert.Greater(t, fileInfo.Size(), int64(0), "generated db file size is %d", fileInfo.Size())
 
+	expectedSchemaVersion := strconv.Itoa(db.SchemaVersion)
 	db := openDb(t, tempDbFile)
 	defer db.Close()
 
@@ -49,10 +75,110 @@ func TestGenerate(t *testing.T) {
 	result = db.QueryRow(CountRadicalsQuery)
 	err = result.Scan(&rowCount)
 	assert.NoError(t, err, "failed querying 'radicals' table row count.")
-	assert.Equal(t, 10, rowCount)
+	assert.Equal(t, 6, rowCount)
+
+	result = db.QueryRow(CountShortCodesQuery)
+	err = result.Scan(&rowCount)
+	assert.NoError(t, err, "failed querying 'shortcodes' table row count.")
+	assert.Equal(t, 2, rowCount)
+
+	result = db.QueryRow(GetPhraseFrequencyQuery, "香港")
+	var frequency int
+	err = result.Scan(&frequency)
+	assert.NoError(t, err, "failed querying 'phrases' table.")
+	assert.Equal(t, 9800, frequency, "duplicated phrase keeps the highest frequency")
+
+	phraseCodes := make([]string, 0)
+	codeRows, err := db.Query(SelectPhraseCodesQuery, "倉頡")
+	require.NoError(t, err, "failed querying 'phrase_codes' table.")
+	defer codeRows.Close()
+	for codeRows.Next() {
+		var code string
+		require.NoError(t, codeRows.Scan(&code))
+		phraseCodes = append(phraseCodes, code)
+	}
+	assert.ElementsMatch(t, []string{"3:og", "3:orgc", "5:og", "5:orgc"}, phraseCodes)
+
+	longCodes := make([]string, 0)
+	longRows, err := db.Query(SelectPhraseCodesQuery, "倉頡倉")
+	require.NoError(t, err, "failed querying 'phrase_codes' table.")
+	defer longRows.Close()
+	for longRows.Next() {
+		var code string
+		require.NoError(t, longRows.Scan(&code))
+		longCodes = append(longCodes, code)
+	}
+	assert.ElementsMatch(t, []string{"3:ogo", "5:ogo"}, longCodes, "codes longer than 5 radicals are skipped")
+
+	result = db.QueryRow(CountPhraseCodesQuery)
+	err = result.Scan(&rowCount)
+	assert.NoError(t, err, "failed querying 'phrase_codes' table row count.")
+	assert.Equal(t, 6, rowCount, "phrases with characters without code are skipped")
+
+	metadata := make(map[string]string)
+	rows, err := db.Query(SelectMetadataQuery)
+	require.NoError(t, err, "failed querying 'metadata' table.")
+	defer rows.Close()
+	for rows.Next() {
+		var key, value string
+		require.NoError(t, rows.Scan(&key, &value))
+		metadata[key] = value
+	}
+	assert.Equal(t, expectedSchemaVersion, metadata[models.MetadataSchemaVersion])
+	assert.Len(t, metadata[models.MetadataTableRevision], 64)
+	generatedAt, err := time.Parse(time.RFC3339, metadata[models.MetadataGeneratedAt])
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), generatedAt, time.Minute)
+}
+
+func TestGenerateWithVariants(t *testing.T) {
+	congkitTable := loadTestTableData(t)
+	congkitTable = append(congkitTable,
+		data.Entry{Fields: strings.Split("產 产 1 1 0 0 1 0 0 0 0 yhhqm ykmhm NA 19547", " ")},
+		data.Entry{Fields: strings.Split("産 产 1 0 0 0 1 0 0 0 0 yhhqm yhhqm NA 0", " ")},
+	)
+
+	tempDbFile := path.Join(t.TempDir(), "test.db")
+	variants := [][]string{
+		{"產", "kZVariant", "産"},
+		{"産", "kZVariant", "產"},
+		{"倉", "kSemanticVariant", "仺"},
+	}
+	err := db.Generate(congkitTable, tempDbFile, db.WithVariants(variants))
+	require.NoError(t, err, "failed generating database")
+
+	db := openDb(t, tempDbFile)
+	defer db.Close()
+
+	rows, err := db.Query(SelectVariantsQuery)
+	require.NoError(t, err, "failed querying 'variants' table.")
+	defer rows.Close()
+	variantPairs := make([]string, 0)
+	for rows.Next() {
+		var pair string
+		require.NoError(t, rows.Scan(&pair))
+		variantPairs = append(variantPairs, pair)
+	}
+	assert.ElementsMatch(t, []string{"產産:kZVariant", "産產:kZVariant"}, variantPairs,
+		"variants of characters without code are skipped")
+}
+
+func TestGenerateWithLogger(t *testing.T) {
+	congkitTable := loadTestTableData(t)
+	congkitTable[0].Fields[2] = "x"
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	tempDbFile := path.Join(t.TempDir(), "test.db")
+	err := db.Generate(congkitTable, tempDbFile, db.WithLogger(logger))
+	require.NoError(t, err, "failed generating database")
+
+	assert.Contains(t, logs.String(), `"level":"WARN","msg":"unable to convert column to int","line":3,"char":"倉","column":3,"value":"x"`)
+	assert.Contains(t, logs.String(), `"msg":"db file generated"`)
 }
 
-func loadTestTableData(t *testing.T) [][]string {
+func loadTestTableData(t *testing.T) []data.Entry {
 	testTable, err := testdataCongkitTable.Open("testdata/table.txt")
 	require.NoError(t, err, "failed loading test data")
 	congkitTable, err := data.ReadTable(testTable)
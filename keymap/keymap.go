@@ -0,0 +1,164 @@
+// Package keymap translates the keys typed on other keyboard layouts
+// to the QWERTY keys at the same physical positions, so that the radicals
+// stay at their positions on the keyboard, e.g. 'o' typed on Dvorak is 's' (尸).
+package keymap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Errors on loading a keymap
+var (
+	ErrUnknownLayout  = errors.New("keymap: unknown layout")
+	ErrInvalidMapping = errors.New("keymap: invalid mapping")
+)
+
+// Keymap maps the typed keys to the QWERTY keys.
+// Keys which are not mapped are kept as typed.
+type Keymap struct {
+	name string
+	keys map[rune]rune
+}
+
+// Built-in layouts, mapping the unshifted keys of the main block
+var (
+	QWERTY = mustNew("qwerty", "", "")
+	Dvorak = mustNew("dvorak",
+		`',.pyfgcrl/=aoeuidhtns-;qjkxbmwvz[]`,
+		`qwertyuiop[]asdfghjkl;'zxcvbnm,./-=`)
+	Colemak = mustNew("colemak",
+		`fpgjluy;rstdneiok`,
+		`ertyuiopsdfgjkl;n`)
+	// AZERTY is the French layout, the digits are mapped from the unshifted number row.
+	AZERTY = mustNew("azerty",
+		`&é"'(-è_çà)azqmùw,;:!^$`,
+		`1234567890-qwa;'zm,./[]`)
+)
+
+var builtins = map[string]*Keymap{
+	QWERTY.name:  QWERTY,
+	Dvorak.name:  Dvorak,
+	Colemak.name: Colemak,
+	AZERTY.name:  AZERTY,
+}
+
+// mustNew creates a built-in keymap mapping each typed key to the QWERTY key
+// at the same index. It panics if the numbers of keys differ.
+// The mappings from users are read by Parse.
+func mustNew(name, typed, qwerty string) *Keymap {
+	from, to := []rune(typed), []rune(qwerty)
+	if len(from) != len(to) {
+		panic(fmt.Sprintf("keymap: %s has %d typed keys but %d QWERTY keys", name, len(from), len(to)))
+	}
+
+	k := &Keymap{name: name, keys: make(map[rune]rune, len(from))}
+	for i, key := range from {
+		if key != to[i] {
+			k.keys[key] = to[i]
+		}
+	}
+
+	return k
+}
+
+// Builtin returns the built-in keymap of the layout name, case-insensitively.
+func Builtin(name string) (*Keymap, error) {
+	k, ok := builtins[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownLayout, name)
+	}
+
+	return k, nil
+}
+
+// Builtins lists the names of the built-in layouts.
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Load returns the built-in keymap of the name,
+// or reads the keymap file at the path if it is not a built-in layout.
+func Load(nameOrPath string) (*Keymap, error) {
+	if k, err := Builtin(nameOrPath); err == nil {
+		return k, nil
+	}
+
+	f, err := os.Open(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownLayout, nameOrPath)
+	}
+	defer f.Close()
+
+	return Parse(strings.TrimSuffix(filepath.Base(nameOrPath), filepath.Ext(nameOrPath)), f)
+}
+
+// Parse reads a keymap of lines with a typed key and the QWERTY key
+// separated by a space, e.g. "o s". Empty lines and lines starting with '#' are skipped.
+func Parse(name string, r io.Reader) (*Keymap, error) {
+	k := &Keymap{name: name, keys: make(map[rune]rune)}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || utf8.RuneCountInString(fields[0]) != 1 || utf8.RuneCountInString(fields[1]) != 1 {
+			return nil, fmt.Errorf("line %d: %w", lineNum, ErrInvalidMapping)
+		}
+		typed, _ := utf8.DecodeRuneInString(fields[0])
+		qwerty, _ := utf8.DecodeRuneInString(fields[1])
+		if _, ok := k.keys[typed]; ok {
+			return nil, fmt.Errorf("line %d: %w: '%c' is mapped twice", lineNum, ErrInvalidMapping, typed)
+		}
+		k.keys[typed] = qwerty
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading keymap. %w", err)
+	}
+
+	return k, nil
+}
+
+// Name is the name of the layout.
+func (k *Keymap) Name() string {
+	return k.name
+}
+
+// Key translates the typed key to the QWERTY key.
+// Upper case letters are translated as their lower case keys
+// and kept in upper case if the QWERTY key is a letter.
+func (k *Keymap) Key(key rune) rune {
+	if qwerty, ok := k.keys[key]; ok {
+		return qwerty
+	}
+	if lower := unicode.ToLower(key); lower != key {
+		if qwerty, ok := k.keys[lower]; ok && unicode.IsLetter(qwerty) {
+			return unicode.ToUpper(qwerty)
+		}
+	}
+
+	return key
+}
+
+// Translate translates all the typed keys in the text.
+func (k *Keymap) Translate(typed string) string {
+	return strings.Map(k.Key, typed)
+}
@@ -0,0 +1,95 @@
+package keymap_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/antonyho/go-congkit/keymap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinLayouts(t *testing.T) {
+	// The physical keys of "oiar hqi" on each layout
+	var testCases = []struct {
+		keymap *keymap.Keymap
+		typed  string
+	}{
+		{keymap.QWERTY, "oiar hqi"},
+		{keymap.Dvorak, "rcap d'c"},
+		{keymap.Colemak, "yuap hqu"},
+		{keymap.AZERTY, "oiqr hai"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.keymap.Name(), func(t *testing.T) {
+			assert.Equal(t, "oiar hqi", testCase.keymap.Translate(testCase.typed))
+		})
+	}
+}
+
+func TestBuiltinLayoutsCoverRadicals(t *testing.T) {
+	for _, name := range keymap.Builtins() {
+		k, err := keymap.Builtin(name)
+		require.NoError(t, err)
+
+		radicals := make(map[rune]bool)
+		for r := rune(0x20); r < 0x250; r++ {
+			if qwerty := k.Key(r); qwerty >= 'a' && qwerty <= 'z' {
+				radicals[qwerty] = true
+			}
+		}
+		assert.Len(t, radicals, 26, "%s reaches all the radicals", name)
+	}
+}
+
+func TestKeymapKey(t *testing.T) {
+	assert.Equal(t, 's', keymap.Dvorak.Key('o'))
+	assert.Equal(t, 'S', keymap.Dvorak.Key('O'))
+	assert.Equal(t, 'w', keymap.Dvorak.Key(','))
+	assert.Equal(t, '1', keymap.AZERTY.Key('&'))
+	assert.Equal(t, '日', keymap.Dvorak.Key('日'), "keys not mapped are kept")
+}
+
+func TestBuiltin(t *testing.T) {
+	k, err := keymap.Builtin("Dvorak")
+	require.NoError(t, err)
+	assert.Same(t, keymap.Dvorak, k)
+
+	_, err = keymap.Builtin("bépo")
+	assert.ErrorIs(t, err, keymap.ErrUnknownLayout)
+
+	assert.Equal(t, []string{"azerty", "colemak", "dvorak", "qwerty"}, keymap.Builtins())
+}
+
+func TestParse(t *testing.T) {
+	k, err := keymap.Parse("custom", strings.NewReader("# swap a and s\na s\n\ns a\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "custom", k.Name())
+	assert.Equal(t, "sad", k.Translate("asd"))
+
+	_, err = keymap.Parse("custom", strings.NewReader("a s\nbc d\n"))
+	assert.ErrorIs(t, err, keymap.ErrInvalidMapping)
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = keymap.Parse("custom", strings.NewReader("a s\na d\n"))
+	assert.ErrorIs(t, err, keymap.ErrInvalidMapping)
+}
+
+func TestLoad(t *testing.T) {
+	k, err := keymap.Load("colemak")
+	require.NoError(t, err)
+	assert.Same(t, keymap.Colemak, k)
+
+	file := path.Join(t.TempDir(), "mine.txt")
+	require.NoError(t, os.WriteFile(file, []byte("a s\n"), 0o644))
+	k, err = keymap.Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, "mine", k.Name())
+	assert.Equal(t, "s", k.Translate("a"))
+
+	_, err = keymap.Load(path.Join(t.TempDir(), "notexist.txt"))
+	assert.ErrorIs(t, err, keymap.ErrUnknownLayout)
+}
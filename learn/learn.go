@@ -0,0 +1,378 @@
+// Package learn provides an opt-in learner of the candidates a user selects,
+// which the engine uses to rank the frequently selected candidates first.
+// Nothing is recorded until the user gives consent.
+package learn
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/antonyho/go-congkit/radical"
+
+	// SQLite3 driver for the learner, the learned data is stored in SQlite3.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Errors on learning
+var (
+	ErrNoConsent = errors.New("learn: consent not given")
+)
+
+const (
+	CreateSelectionsTableQuery = `
+	CREATE TABLE IF NOT EXISTS selections (
+		radicals TEXT NOT NULL,
+		text TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		score REAL NOT NULL,
+		selected_at INTEGER NOT NULL,
+		PRIMARY KEY (radicals, text)
+	);
+	`
+
+	CreateSettingsTableQuery = `
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT NOT NULL PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	`
+
+	GetSelectionQuery = `SELECT count, score, selected_at FROM selections WHERE radicals = ? AND text = ?;`
+
+	PutSelectionQuery = `INSERT OR REPLACE INTO selections (radicals, text, count, score, selected_at) VALUES (?, ?, ?, ?, ?);`
+
+	GetSelectionsFromRadicalsQuery = `SELECT radicals, text, count, score, selected_at FROM selections WHERE radicals = ?;`
+
+	ListSelectionsQuery = `SELECT radicals, text, count, score, selected_at FROM selections ORDER BY radicals, text;`
+
+	ForgetSelectionQuery = `DELETE FROM selections WHERE radicals = ? AND text = ?;`
+
+	ResetSelectionsQuery = `DELETE FROM selections;`
+
+	GetSettingQuery = `SELECT value FROM settings WHERE key = ?;`
+
+	PutSettingQuery = `INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?);`
+
+	DeleteSettingQuery = `DELETE FROM settings WHERE key = ?;`
+)
+
+// SettingConsent is the settings key of the time the consent was given.
+const SettingConsent = "consent"
+
+// DefaultHalfLife is the default time for the score of a selection to decay to half.
+const DefaultHalfLife = 30 * 24 * time.Hour
+
+// Selection is the learned selections of a text for the radicals.
+type Selection struct {
+	Radicals     string
+	Text         string
+	Count        int       // Number of times the text was selected
+	Score        float64   // Count decayed by the time since each selection
+	LastSelected time.Time // Time of the last selection
+}
+
+type Option func(*Learner)
+
+// WithHalfLife sets the time for the score of a selection to decay to half.
+// A zero half life disables the decay.
+func WithHalfLife(halfLife time.Duration) Option {
+	return func(l *Learner) {
+		l.halfLife = halfLife
+	}
+}
+
+// WithClock sets the function giving the current time. The default is time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(l *Learner) {
+		l.now = now
+	}
+}
+
+// Learner records the selected candidates in a SQLite3 database file.
+// A learner is safe for concurrent use.
+type Learner struct {
+	db       *sql.DB
+	halfLife time.Duration
+	now      func() time.Time
+}
+
+// Open opens the learned data at the path, creating it if it does not exist.
+func Open(path string, options ...Option) (*Learner, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open learned data at %s. %w", path, err)
+	}
+
+	for _, query := range []string{CreateSelectionsTableQuery, CreateSettingsTableQuery} {
+		if _, err := db.Exec(query); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("error creating learned data tables. %w", err)
+		}
+	}
+
+	l := &Learner{
+		db:       db,
+		halfLife: DefaultHalfLife,
+		now:      time.Now,
+	}
+	for _, option := range options {
+		option(l)
+	}
+
+	return l, nil
+}
+
+// Close closes the learned data.
+func (l *Learner) Close() error {
+	return l.db.Close()
+}
+
+// Consent records the consent of the user to learn the selections.
+func (l *Learner) Consent() error {
+	if _, err := l.db.Exec(PutSettingQuery, SettingConsent, l.now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("error recording consent. %w", err)
+	}
+
+	return nil
+}
+
+// Revoke withdraws the consent and deletes all the learned selections.
+func (l *Learner) Revoke() error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction. %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(DeleteSettingQuery, SettingConsent); err != nil {
+		return fmt.Errorf("error revoking consent. %w", err)
+	}
+	if _, err := tx.Exec(ResetSelectionsQuery); err != nil {
+		return fmt.Errorf("error deleting learned selections. %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Consented reports whether the user has given consent.
+func (l *Learner) Consented() (bool, error) {
+	var value string
+	err := l.db.QueryRow(GetSettingQuery, SettingConsent).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error reading consent. %w", err)
+	}
+
+	return true, nil
+}
+
+// Record learns the selection of the text for the radicals.
+// It returns ErrNoConsent if the user has not given consent.
+func (l *Learner) Record(radicals, text string) error {
+	consented, err := l.Consented()
+	if err != nil {
+		return err
+	}
+	if !consented {
+		return ErrNoConsent
+	}
+
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		return err
+	}
+	if code == "" || text == "" {
+		return nil
+	}
+
+	tx, err := l.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction. %w", err)
+	}
+	defer tx.Rollback()
+
+	now := l.now()
+	var (
+		count      int
+		score      float64
+		selectedAt int64
+	)
+	err = tx.QueryRow(GetSelectionQuery, code, text).Scan(&count, &score, &selectedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error reading selection '%s' '%s'. %w", code, text, err)
+	}
+	if err == nil {
+		score = l.decay(score, time.Unix(0, selectedAt), now)
+	}
+	if _, err := tx.Exec(PutSelectionQuery, code, text, count+1, score+1, now.UnixNano()); err != nil {
+		return fmt.Errorf("error recording selection '%s' '%s'. %w", code, text, err)
+	}
+
+	return tx.Commit()
+}
+
+// Retract reverses one selection of the text for the radicals recorded by Record,
+// e.g. when the commit is undone. The selection is forgotten when its count drops to 0.
+// It returns ErrNoConsent if the user has not given consent.
+func (l *Learner) Retract(radicals, text string) error {
+	consented, err := l.Consented()
+	if err != nil {
+		return err
+	}
+	if !consented {
+		return ErrNoConsent
+	}
+
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		return err
+	}
+
+	tx, err := l.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction. %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		count      int
+		score      float64
+		selectedAt int64
+	)
+	err = tx.QueryRow(GetSelectionQuery, code, text).Scan(&count, &score, &selectedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading selection '%s' '%s'. %w", code, text, err)
+	}
+	if count <= 1 {
+		if _, err := tx.Exec(ForgetSelectionQuery, code, text); err != nil {
+			return fmt.Errorf("error forgetting selection '%s' '%s'. %w", code, text, err)
+		}
+		return tx.Commit()
+	}
+
+	now := l.now()
+	score = max(l.decay(score, time.Unix(0, selectedAt), now)-1, 0)
+	if _, err := tx.Exec(PutSelectionQuery, code, text, count-1, score, now.UnixNano()); err != nil {
+		return fmt.Errorf("error retracting selection '%s' '%s'. %w", code, text, err)
+	}
+
+	return tx.Commit()
+}
+
+// Scores gives the decayed scores of the selected texts for the radicals.
+// The radicals must be in the keys 'a' to 'z'.
+// No scores are given without consent.
+func (l *Learner) Scores(radicals string) (map[string]float64, error) {
+	consented, err := l.Consented()
+	if err != nil || !consented {
+		return nil, err
+	}
+
+	rows, err := l.db.Query(GetSelectionsFromRadicalsQuery, radicals)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up selections of '%s'. %w", radicals, err)
+	}
+	selections, err := l.scanSelections(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64, len(selections))
+	for _, selection := range selections {
+		scores[selection.Text] = selection.Score
+	}
+
+	return scores, nil
+}
+
+// Selections lists all the learned selections with the decayed scores.
+func (l *Learner) Selections() ([]Selection, error) {
+	rows, err := l.db.Query(ListSelectionsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error listing selections. %w", err)
+	}
+
+	return l.scanSelections(rows)
+}
+
+// Forget deletes the learned selection of the text for the radicals.
+func (l *Learner) Forget(radicals, text string) error {
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		return err
+	}
+
+	if _, err := l.db.Exec(ForgetSelectionQuery, code, text); err != nil {
+		return fmt.Errorf("error forgetting selection '%s' '%s'. %w", code, text, err)
+	}
+
+	return nil
+}
+
+// Reset deletes all the learned selections. The consent is kept.
+func (l *Learner) Reset() error {
+	if _, err := l.db.Exec(ResetSelectionsQuery); err != nil {
+		return fmt.Errorf("error deleting learned selections. %w", err)
+	}
+
+	return nil
+}
+
+// Export writes all the learned selections for the user to inspect.
+// Each line has the radicals, the text, the count, the decayed score
+// and the time of the last selection separated by a space.
+func (l *Learner) Export(w io.Writer) error {
+	selections, err := l.Selections()
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, s := range selections {
+		fmt.Fprintf(bw, "%s %s %d %.4f %s\n",
+			s.Radicals, s.Text, s.Count, s.Score, s.LastSelected.UTC().Format(time.RFC3339))
+	}
+
+	return bw.Flush()
+}
+
+// decay decays the score from the time of the selection to now.
+func (l *Learner) decay(score float64, selected, now time.Time) float64 {
+	elapsed := now.Sub(selected)
+	if l.halfLife <= 0 || elapsed <= 0 {
+		return score
+	}
+
+	return score * math.Pow(0.5, float64(elapsed)/float64(l.halfLife))
+}
+
+func (l *Learner) scanSelections(rows *sql.Rows) ([]Selection, error) {
+	defer rows.Close()
+
+	now := l.now()
+	selections := make([]Selection, 0)
+	for rows.Next() {
+		var (
+			s          Selection
+			selectedAt int64
+		)
+		if err := rows.Scan(&s.Radicals, &s.Text, &s.Count, &s.Score, &selectedAt); err != nil {
+			return nil, fmt.Errorf("error reading selections. %w", err)
+		}
+		s.LastSelected = time.Unix(0, selectedAt)
+		s.Score = l.decay(s.Score, s.LastSelected, now)
+		selections = append(selections, s)
+	}
+
+	return selections, rows.Err()
+}
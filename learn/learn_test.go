@@ -0,0 +1,133 @@
+package learn_test
+
+import (
+	"bytes"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/antonyho/go-congkit/learn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a settable time for the learner.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func openLearner(t *testing.T, options ...learn.Option) *learn.Learner {
+	t.Helper()
+	learner, err := learn.Open(path.Join(t.TempDir(), "learn.db"), options...)
+	require.NoError(t, err, "failed opening learned data")
+	t.Cleanup(func() { learner.Close() })
+
+	return learner
+}
+
+func TestLearnerConsent(t *testing.T) {
+	learner := openLearner(t)
+
+	consented, err := learner.Consented()
+	require.NoError(t, err)
+	assert.False(t, consented)
+	assert.ErrorIs(t, learner.Record("hqi", "牫"), learn.ErrNoConsent)
+
+	require.NoError(t, learner.Consent())
+	consented, err = learner.Consented()
+	require.NoError(t, err)
+	assert.True(t, consented)
+	require.NoError(t, learner.Record("hqi", "牫"))
+
+	require.NoError(t, learner.Revoke())
+	consented, err = learner.Consented()
+	require.NoError(t, err)
+	assert.False(t, consented)
+	selections, err := learner.Selections()
+	require.NoError(t, err)
+	assert.Empty(t, selections, "revoking consent deletes the learned data")
+}
+
+func TestLearnerRecord(t *testing.T) {
+	learner := openLearner(t, learn.WithHalfLife(0))
+	require.NoError(t, learner.Consent())
+
+	require.NoError(t, learner.Record("hqi", "牫"))
+	require.NoError(t, learner.Record("竹手戈", "牫"))
+	require.NoError(t, learner.Record("hqi", "我"))
+
+	scores, err := learner.Scores("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"牫": 2, "我": 1}, scores)
+
+	require.NoError(t, learner.Retract("hqi", "牫"))
+	scores, err = learner.Scores("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"牫": 1, "我": 1}, scores, "retracting decrements the selection")
+
+	require.NoError(t, learner.Retract("hqi", "我"))
+	require.NoError(t, learner.Retract("hqi", "我"), "retracting a forgotten selection does nothing")
+	scores, err = learner.Scores("hqi")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"牫": 1}, scores, "the last selection is forgotten")
+
+	require.NoError(t, learner.Forget("hqi", "牫"))
+	scores, err = learner.Scores("hqi")
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+
+	require.NoError(t, learner.Record("hqi", "我"))
+	require.NoError(t, learner.Reset())
+	scores, err = learner.Scores("hqi")
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+	consented, err := learner.Consented()
+	require.NoError(t, err)
+	assert.True(t, consented, "reset keeps the consent")
+}
+
+func TestLearnerDecay(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	learner := openLearner(t, learn.WithHalfLife(24*time.Hour), learn.WithClock(c.Now))
+	require.NoError(t, learner.Consent())
+
+	require.NoError(t, learner.Record("hqi", "牫"))
+	require.NoError(t, learner.Record("hqi", "牫"))
+	c.now = c.now.Add(24 * time.Hour)
+	require.NoError(t, learner.Record("hqi", "我"))
+
+	scores, err := learner.Scores("hqi")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, scores["牫"], 1e-9)
+	assert.InDelta(t, 1.0, scores["我"], 1e-9)
+
+	c.now = c.now.Add(24 * time.Hour)
+	require.NoError(t, learner.Record("hqi", "牫"))
+	selections, err := learner.Selections()
+	require.NoError(t, err)
+	require.Len(t, selections, 2)
+	assert.Equal(t, "我", selections[0].Text)
+	assert.InDelta(t, 0.5, selections[0].Score, 1e-9)
+	assert.Equal(t, "牫", selections[1].Text)
+	assert.Equal(t, 3, selections[1].Count)
+	assert.InDelta(t, 1.5, selections[1].Score, 1e-9)
+	assert.True(t, c.now.Equal(selections[1].LastSelected))
+}
+
+func TestLearnerExport(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	learner := openLearner(t, learn.WithClock(c.Now))
+	require.NoError(t, learner.Consent())
+	require.NoError(t, learner.Record("oiar", "倉"))
+	require.NoError(t, learner.Record("hqi", "我"))
+
+	var exported bytes.Buffer
+	require.NoError(t, learner.Export(&exported))
+	assert.Equal(t,
+		"hqi 我 1 1.0000 2024-01-01T00:00:00Z\noiar 倉 1 1.0000 2024-01-01T00:00:00Z\n",
+		exported.String())
+}
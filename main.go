@@ -4,8 +4,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/keymap"
+	"github.com/antonyho/go-congkit/radical"
 )
 
 var (
@@ -13,6 +16,10 @@ var (
 	simplified bool
 	easy       bool
 	prediction bool
+	fuzzy      bool
+	radicals   bool
+	group      bool
+	layout     string
 	db         string
 )
 
@@ -27,6 +34,10 @@ const (
 	SimplifiedUsage  = "Output simplified Chinese word"
 	EasyIMUsage      = "Use 'Easy' input method"
 	PredicationUsage = "Predict the possible typing word"
+	FuzzyUsage       = "Also list words of mistyped radicals"
+	RadicalsUsage    = "Show the radicals of the input"
+	GroupUsage       = "Group the variant characters"
+	KeymapUsage      = "Keyboard layout of the input (qwerty/dvorak/colemak/azerty) or a keymap file path"
 	DBUsage          = "Custom database file path"
 )
 
@@ -43,6 +54,18 @@ func init() {
 	flag.BoolVar(&prediction, "prediction", false, PredicationUsage)
 	flag.BoolVar(&prediction, "p", false, PredicationUsage)
 
+	flag.BoolVar(&fuzzy, "fuzzy", false, FuzzyUsage)
+	flag.BoolVar(&fuzzy, "f", false, FuzzyUsage)
+
+	flag.BoolVar(&radicals, "radicals", false, RadicalsUsage)
+	flag.BoolVar(&radicals, "r", false, RadicalsUsage)
+
+	flag.BoolVar(&group, "group", false, GroupUsage)
+	flag.BoolVar(&group, "g", false, GroupUsage)
+
+	flag.StringVar(&layout, "keymap", "", KeymapUsage)
+	flag.StringVar(&layout, "k", "", KeymapUsage)
+
 	flag.StringVar(&db, "database", DefaultDB, DBUsage)
 	flag.StringVar(&db, "d", DefaultDB, DBUsage)
 
@@ -54,7 +77,7 @@ func main() {
 	flag.Parse()
 
 	if flag.NArg() < 1 {
-		fmt.Printf("%s [congkit_radicals]\n\n", os.Args[0])
+		fmt.Printf("%s [congkit_radicals...]\n\n", os.Args[0])
 		flag.Usage()
 		os.Exit(0)
 	}
@@ -83,20 +106,74 @@ func main() {
 		options = append(options, engine.WithPrediction())
 	}
 
+	if fuzzy {
+		options = append(options, engine.WithFuzzy())
+	}
+
+	sequence := strings.Join(flag.Args(), " ")
+	if layout != "" {
+		k, err := keymap.Load(layout)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		// The delimiters may be radical keys on the layout, only spaces separate the codes.
+		codes := strings.Fields(sequence)
+		for i, code := range codes {
+			codes[i] = k.Translate(code)
+		}
+		sequence = strings.Join(codes, " ")
+	}
+
 	eng := engine.New(options...)
 	defer eng.Close()
-	result, err := eng.Encode(flag.Arg(0))
+	segments, err := eng.EncodeSequence(sequence)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	resultStrings := make([]string, len(result))
-	for i, r := range result {
-		resultStrings[i] = string(r)
+	failed := false
+	for _, segment := range segments {
+		if segment.Err != nil {
+			fmt.Printf("%s: %v\n", segment.Radicals, segment.Err)
+			failed = true
+			continue
+		}
+
+		resultStrings := make([]string, len(segment.Results))
+		for i, r := range segment.Results {
+			resultStrings[i] = string(r)
+		}
+		if group {
+			// Variants follow their primary characters in brackets, e.g. 產(産)
+			groups, err := eng.GroupVariants(segment.Results)
+			if err != nil {
+				fmt.Printf("%s: %v\n", segment.Radicals, err)
+				failed = true
+				continue
+			}
+			resultStrings = make([]string, len(groups))
+			for i, g := range groups {
+				resultStrings[i] = string(g.Primary)
+				if len(g.Variants) > 0 {
+					resultStrings[i] += "(" + string(g.Variants) + ")"
+				}
+			}
+		}
+
+		if radicals {
+			fmt.Printf("%s %v\n", radical.Annotate(segment.Radicals), resultStrings)
+		} else if len(segments) > 1 {
+			fmt.Printf("%s %v\n", segment.Radicals, resultStrings)
+		} else {
+			fmt.Println(resultStrings)
+		}
 	}
 
-	fmt.Println(resultStrings)
+	if failed {
+		os.Exit(1)
+	}
 }
 
 func helpFunc(_ string) error {
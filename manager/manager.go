@@ -0,0 +1,329 @@
+// Package manager hosts the input sessions of many users on a server.
+// The sessions share one encoder, usually an *engine.Engine and its database,
+// idle sessions expire and the estimated memory of all sessions is capped.
+package manager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/antonyho/go-congkit/session"
+)
+
+// Errors on managing the sessions
+var (
+	ErrSessionNotFound = errors.New("manager: session not found")
+	ErrSessionExists   = errors.New("manager: session exists")
+	ErrMemoryLimit     = errors.New("manager: memory limit reached")
+)
+
+// Manager defaults
+const (
+	DefaultIdleTimeout = 30 * time.Minute
+	// SessionOverhead is the estimated memory in bytes of an empty session.
+	SessionOverhead = 2048
+)
+
+// Stats is the counts of the sessions of a manager.
+type Stats struct {
+	Sessions int   // Number of the open sessions
+	Created  int   // Number of the sessions created
+	Closed   int   // Number of the sessions closed by Close
+	Expired  int   // Number of the sessions expired for being idle
+	Evicted  int   // Number of the sessions evicted for the memory limit
+	Memory   int64 // Estimated memory in bytes of the open sessions, which can exceed the soft cap
+}
+
+type Option func(*Manager)
+
+// WithIdleTimeout sets the time after the last use for a session to expire.
+// A zero timeout keeps the idle sessions. The default is DefaultIdleTimeout.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		m.idleTimeout = timeout
+	}
+}
+
+// WithMaxMemory sets a soft cap on the estimated memory in bytes of all the sessions.
+// The least recently used idle sessions are evicted for a new session
+// or a session growing over the cap. A new session is refused over the cap,
+// but a session growing in Do is kept over the cap when no session can be evicted.
+// A zero cap is unlimited, which is the default.
+func WithMaxMemory(bytes int64) Option {
+	return func(m *Manager) {
+		m.maxMemory = bytes
+	}
+}
+
+// WithSessionOptions sets the options of the created sessions.
+func WithSessionOptions(options ...session.Option) Option {
+	return func(m *Manager) {
+		m.sessionOptions = options
+	}
+}
+
+// WithClock sets the function giving the current time. The default is time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) {
+		m.now = now
+	}
+}
+
+// Manager creates and keeps the sessions by ID.
+// A manager is safe for concurrent use, the operations on the same session
+// are serialised. The shared encoder must be safe for concurrent use,
+// an *engine.Engine is as long as its modes are not changed.
+type Manager struct {
+	encoder        session.Encoder
+	sessionOptions []session.Option
+	idleTimeout    time.Duration
+	maxMemory      int64
+	now            func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*entry
+	stats    Stats
+}
+
+// entry is a managed session.
+type entry struct {
+	mu       sync.Mutex // Serialises the operations on the session
+	session  *session.Session
+	closed   atomic.Bool // The session is removed from the manager
+	lastUsed time.Time   // Guarded by the manager
+	memory   int64       // Guarded by the manager
+}
+
+// New creates a manager of the sessions encoding with the shared encoder.
+func New(encoder session.Encoder, options ...Option) *Manager {
+	m := &Manager{
+		encoder:     encoder,
+		idleTimeout: DefaultIdleTimeout,
+		now:         time.Now,
+		sessions:    make(map[string]*entry),
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	return m
+}
+
+// Create creates a session with a new random ID.
+func (m *Manager) Create() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating session ID. %w", err)
+	}
+	id := hex.EncodeToString(b)
+
+	return id, m.CreateWithID(id)
+}
+
+// CreateWithID creates a session with the ID given by the client.
+func (m *Manager) CreateWithID(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[id]; ok {
+		return fmt.Errorf("%w: %s", ErrSessionExists, id)
+	}
+	if err := m.reserve(SessionOverhead); err != nil {
+		return err
+	}
+
+	m.sessions[id] = &entry{
+		session:  session.New(m.encoder, m.sessionOptions...),
+		lastUsed: m.now(),
+		memory:   SessionOverhead,
+	}
+	m.stats.Created++
+	m.stats.Memory += SessionOverhead
+
+	return nil
+}
+
+// Do runs the function on the session of the ID.
+// The function must not keep the session after returning.
+// The memory cap is soft for the session: when it grows over the cap and
+// no idle session can be evicted, the change is kept and ErrMemoryLimit
+// is joined to the error of the function.
+func (m *Manager) Do(id string, fn func(*session.Session) error) error {
+	m.mu.Lock()
+	e, ok := m.sessions[id]
+	if ok {
+		e.lastUsed = m.now()
+	}
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed.Load() {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	err := fn(e.session)
+	memory := estimate(e.session)
+
+	m.mu.Lock()
+	if m.sessions[id] == e {
+		// The session is locked here so it is never evicted for its own growth.
+		if growth := memory - e.memory; growth > 0 {
+			if reserveErr := m.reserve(growth); reserveErr != nil {
+				err = errors.Join(err, reserveErr)
+			}
+		}
+		m.stats.Memory += memory - e.memory
+		e.memory = memory
+	}
+	m.mu.Unlock()
+
+	return err
+}
+
+// Press handles the key event in the session of the ID.
+func (m *Manager) Press(id string, key session.Key) (events []session.Event, err error) {
+	err = m.Do(id, func(s *session.Session) (pressErr error) {
+		events, pressErr = s.Press(key)
+		return
+	})
+
+	return
+}
+
+// Close removes the session of the ID.
+func (m *Manager) Close(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.sessions[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	m.remove(id, e)
+	m.stats.Closed++
+
+	return nil
+}
+
+// Expire removes the sessions idle for longer than the idle timeout
+// and returns the number of the removed sessions.
+// Sessions in use are not removed.
+func (m *Manager) Expire() int {
+	if m.idleTimeout <= 0 {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expired := 0
+	deadline := m.now().Add(-m.idleTimeout)
+	for id, e := range m.sessions {
+		if e.lastUsed.Before(deadline) && m.tryRemove(id, e) {
+			expired++
+		}
+	}
+	m.stats.Expired += expired
+
+	return expired
+}
+
+// Run expires the idle sessions at the interval until the context is done.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Expire()
+		}
+	}
+}
+
+// Len is the number of the open sessions.
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.sessions)
+}
+
+// Stats returns the counts of the sessions.
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats
+	stats.Sessions = len(m.sessions)
+
+	return stats
+}
+
+// reserve evicts the least recently used sessions not in use
+// until the memory fits in the cap. The manager must be locked.
+func (m *Manager) reserve(memory int64) error {
+	if m.maxMemory <= 0 {
+		return nil
+	}
+
+	if m.stats.Memory+memory <= m.maxMemory {
+		return nil
+	}
+
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return m.sessions[ids[i]].lastUsed.Before(m.sessions[ids[j]].lastUsed)
+	})
+	for _, id := range ids {
+		if m.tryRemove(id, m.sessions[id]) {
+			m.stats.Evicted++
+		}
+		if m.stats.Memory+memory <= m.maxMemory {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %d bytes in use", ErrMemoryLimit, m.stats.Memory)
+}
+
+// tryRemove removes the session if it is not in use. The manager must be locked.
+func (m *Manager) tryRemove(id string, e *entry) bool {
+	if !e.mu.TryLock() {
+		return false
+	}
+	defer e.mu.Unlock()
+
+	m.remove(id, e)
+
+	return true
+}
+
+// remove removes the session. The manager must be locked.
+// The session is closed for the operations waiting for it.
+func (m *Manager) remove(id string, e *entry) {
+	delete(m.sessions, id)
+	m.stats.Memory -= e.memory
+	e.closed.Store(true)
+}
+
+// estimate estimates the memory of the session from the size of its state.
+func estimate(s *session.Session) int64 {
+	return SessionOverhead + int64(s.Size())
+}
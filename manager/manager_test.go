@@ -0,0 +1,221 @@
+package manager_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/antonyho/go-congkit/manager"
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEncoder encodes from a fixed table.
+type fakeEncoder map[string][]rune
+
+func (f fakeEncoder) Encode(radicals string) ([]rune, error) {
+	return f[radicals], nil
+}
+
+var encoder = fakeEncoder{
+	"h":   {'竹'},
+	"hq":  {'秉', '乎'},
+	"hqi": {'我', '牫', '𥫻'},
+}
+
+// clock is a settable time for the manager.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestManagerSessions(t *testing.T) {
+	m := manager.New(encoder)
+
+	id, err := m.Create()
+	require.NoError(t, err)
+	assert.Len(t, id, 32)
+	require.NoError(t, m.CreateWithID("client"))
+	assert.ErrorIs(t, m.CreateWithID("client"), manager.ErrSessionExists)
+	assert.Equal(t, 2, m.Len())
+
+	for _, key := range []session.Key{'h', 'q', 'i'} {
+		_, err = m.Press(id, key)
+		require.NoError(t, err)
+	}
+	events, err := m.Press(id, '2')
+	require.NoError(t, err)
+	assert.Equal(t, session.Event{Type: session.EventCommit, Text: "牫"}, events[0])
+
+	require.NoError(t, m.Do("client", func(s *session.Session) error {
+		assert.Empty(t, s.Preedit(), "sessions are separate")
+		return nil
+	}))
+
+	require.NoError(t, m.Close(id))
+	_, err = m.Press(id, 'h')
+	assert.ErrorIs(t, err, manager.ErrSessionNotFound)
+	assert.ErrorIs(t, m.Close(id), manager.ErrSessionNotFound)
+
+	stats := m.Stats()
+	assert.Equal(t, 1, stats.Sessions)
+	assert.Equal(t, 2, stats.Created)
+	assert.Equal(t, 1, stats.Closed)
+	assert.GreaterOrEqual(t, stats.Memory, int64(manager.SessionOverhead))
+	assert.Less(t, stats.Memory, int64(2*manager.SessionOverhead))
+}
+
+func TestManagerSessionOptions(t *testing.T) {
+	m := manager.New(encoder, manager.WithSessionOptions(session.WithMode(session.ModeEnglish)))
+	id, err := m.Create()
+	require.NoError(t, err)
+
+	events, err := m.Press(id, 'h')
+	require.NoError(t, err)
+	assert.Equal(t, session.EventPassthrough, events[0].Type)
+}
+
+func TestManagerExpire(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := manager.New(encoder, manager.WithIdleTimeout(time.Minute), manager.WithClock(c.Now))
+
+	require.NoError(t, m.CreateWithID("idle"))
+	require.NoError(t, m.CreateWithID("active"))
+	c.Add(50 * time.Second)
+	_, err := m.Press("active", 'h')
+	require.NoError(t, err)
+	c.Add(20 * time.Second)
+
+	assert.Equal(t, 1, m.Expire())
+	assert.Equal(t, 1, m.Len())
+	_, err = m.Press("idle", 'h')
+	assert.ErrorIs(t, err, manager.ErrSessionNotFound)
+	_, err = m.Press("active", 'q')
+	assert.NoError(t, err)
+	assert.Equal(t, 1, m.Stats().Expired)
+}
+
+func TestManagerExpireSkipsSessionsInUse(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := manager.New(encoder, manager.WithIdleTimeout(time.Minute), manager.WithClock(c.Now))
+	require.NoError(t, m.CreateWithID("busy"))
+
+	require.NoError(t, m.Do("busy", func(*session.Session) error {
+		c.Add(2 * time.Minute)
+		assert.Zero(t, m.Expire())
+		return nil
+	}))
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestManagerRun(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := manager.New(encoder, manager.WithIdleTimeout(time.Minute), manager.WithClock(c.Now))
+	require.NoError(t, m.CreateWithID("idle"))
+	c.Add(2 * time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return m.Len() == 0 }, time.Second, time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestManagerMaxMemory(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := manager.New(encoder, manager.WithMaxMemory(2*manager.SessionOverhead+100), manager.WithClock(c.Now))
+
+	require.NoError(t, m.CreateWithID("first"))
+	c.Add(time.Second)
+	require.NoError(t, m.CreateWithID("second"))
+	c.Add(time.Second)
+	_, err := m.Press("first", 'h')
+	require.NoError(t, err)
+	assert.Greater(t, m.Stats().Memory, int64(2*manager.SessionOverhead))
+
+	require.NoError(t, m.CreateWithID("third"))
+	assert.Equal(t, 2, m.Len())
+	_, err = m.Press("second", 'h')
+	assert.ErrorIs(t, err, manager.ErrSessionNotFound, "the least recently used session is evicted")
+	assert.Equal(t, 1, m.Stats().Evicted)
+
+	m = manager.New(encoder, manager.WithMaxMemory(manager.SessionOverhead))
+	require.NoError(t, m.CreateWithID("busy"))
+	require.NoError(t, m.Do("busy", func(*session.Session) error {
+		assert.ErrorIs(t, m.CreateWithID("new"), manager.ErrMemoryLimit, "sessions in use are not evicted")
+		return nil
+	}))
+}
+
+func TestManagerMaxMemoryGrowth(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := manager.New(encoder, manager.WithMaxMemory(2*manager.SessionOverhead+2), manager.WithClock(c.Now))
+
+	require.NoError(t, m.CreateWithID("idle"))
+	c.Add(time.Second)
+	require.NoError(t, m.CreateWithID("typing"))
+	c.Add(time.Second)
+	events, err := m.Press("typing", 'h')
+	require.NoError(t, err)
+	assert.NotEmpty(t, events)
+	assert.Equal(t, 1, m.Len(), "the idle session is evicted for the growth")
+	assert.Equal(t, 1, m.Stats().Evicted)
+	assert.LessOrEqual(t, m.Stats().Memory, int64(2*manager.SessionOverhead+2))
+
+	m = manager.New(encoder, manager.WithMaxMemory(manager.SessionOverhead))
+	require.NoError(t, m.CreateWithID("alone"))
+	events, err = m.Press("alone", 'h')
+	assert.ErrorIs(t, err, manager.ErrMemoryLimit)
+	assert.NotEmpty(t, events, "the key is handled over the cap")
+	assert.Equal(t, 1, m.Len(), "the growing session is kept")
+	assert.Greater(t, m.Stats().Memory, int64(manager.SessionOverhead), "the cap is soft for the growth")
+}
+
+func TestManagerConcurrent(t *testing.T) {
+	m := manager.New(encoder)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := m.Create()
+			if !assert.NoError(t, err) {
+				return
+			}
+			for j := 0; j < 50; j++ {
+				for _, key := range []session.Key{'h', 'q', 'i', '1'} {
+					_, err := m.Press(id, key)
+					assert.NoError(t, err)
+				}
+			}
+			assert.NoError(t, m.Close(id))
+		}()
+	}
+	wg.Wait()
+
+	stats := m.Stats()
+	assert.Zero(t, stats.Sessions)
+	assert.Equal(t, 8, stats.Created)
+	assert.Zero(t, stats.Memory)
+}
@@ -0,0 +1,233 @@
+// Package ngram provides a character bigram and trigram model trained
+// from the text of the user, which predicts the next characters.
+// The model is stored in a local SQLite3 database file.
+package ngram
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"unicode"
+
+	// SQLite3 driver for the model, the model is stored in SQlite3.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	CreateBigramsTableQuery = `
+	CREATE TABLE IF NOT EXISTS bigrams (
+		prev TEXT NOT NULL,
+		next TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (prev, next)
+	);
+	`
+
+	CreateTrigramsTableQuery = `
+	CREATE TABLE IF NOT EXISTS trigrams (
+		prev TEXT NOT NULL,
+		next TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (prev, next)
+	);
+	`
+
+	AddBigramQuery = `
+	INSERT INTO bigrams (prev, next, count) VALUES (?, ?, ?)
+	ON CONFLICT (prev, next) DO UPDATE SET count = count + excluded.count;
+	`
+
+	AddTrigramQuery = `
+	INSERT INTO trigrams (prev, next, count) VALUES (?, ?, ?)
+	ON CONFLICT (prev, next) DO UPDATE SET count = count + excluded.count;
+	`
+
+	GetBigramsQuery = `SELECT next, count FROM bigrams WHERE prev = ?;`
+
+	GetTrigramsQuery = `SELECT next, count FROM trigrams WHERE prev = ?;`
+
+	ResetBigramsQuery = `DELETE FROM bigrams;`
+
+	ResetTrigramsQuery = `DELETE FROM trigrams;`
+)
+
+// Weights of the trigram and the bigram probabilities in the scores.
+// The bigram probability backs off the unseen trigrams.
+const (
+	TrigramWeight = 0.7
+	BigramWeight  = 0.3
+)
+
+// gram is a context and its next character.
+type gram struct {
+	prev string
+	next rune
+}
+
+// Model is a character n-gram model.
+// A model is safe for concurrent use.
+type Model struct {
+	db *sql.DB
+}
+
+// Open opens the model at the path, creating it if it does not exist.
+func Open(path string) (*Model, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open n-gram model at %s. %w", path, err)
+	}
+
+	for _, query := range []string{CreateBigramsTableQuery, CreateTrigramsTableQuery} {
+		if _, err := db.Exec(query); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("error creating n-gram model tables. %w", err)
+		}
+	}
+
+	return &Model{db: db}, nil
+}
+
+// Close closes the model.
+func (m *Model) Close() error {
+	return m.db.Close()
+}
+
+// Train counts the character bigrams and trigrams of the text.
+// Only the Han characters are counted, any other character breaks the context.
+func (m *Model) Train(r io.Reader) error {
+	bigrams := make(map[gram]int)
+	trigrams := make(map[gram]int)
+
+	var context []rune
+	reader := bufio.NewReader(r)
+	for {
+		char, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading training text. %w", err)
+		}
+		if !unicode.Is(unicode.Han, char) {
+			context = context[:0]
+			continue
+		}
+
+		if len(context) >= 1 {
+			bigrams[gram{string(context[len(context)-1:]), char}]++
+		}
+		if len(context) >= 2 {
+			trigrams[gram{string(context[len(context)-2:]), char}]++
+		}
+		context = append(context, char)
+		if len(context) > 2 {
+			context = context[1:]
+		}
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction. %w", err)
+	}
+	defer tx.Rollback()
+	for query, counts := range map[string]map[gram]int{AddBigramQuery: bigrams, AddTrigramQuery: trigrams} {
+		for g, count := range counts {
+			if _, err := tx.Exec(query, g.prev, string(g.next), count); err != nil {
+				return fmt.Errorf("error adding n-gram '%s%c'. %w", g.prev, g.next, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Scores gives the probabilities of the next characters after the context,
+// interpolated from the trigrams of the last two characters
+// and the bigrams of the last character of the context.
+func (m *Model) Scores(context string) (map[string]float64, error) {
+	chars := []rune(context)
+	scores := make(map[string]float64)
+	if len(chars) == 0 {
+		return scores, nil
+	}
+
+	if err := m.addScores(scores, GetBigramsQuery, string(chars[len(chars)-1:]), BigramWeight); err != nil {
+		return nil, err
+	}
+	if len(chars) >= 2 {
+		if err := m.addScores(scores, GetTrigramsQuery, string(chars[len(chars)-2:]), TrigramWeight); err != nil {
+			return nil, err
+		}
+	}
+
+	return scores, nil
+}
+
+// Next lists at most the limit of the most likely next characters after the context.
+func (m *Model) Next(context string, limit int) ([]string, error) {
+	scores, err := m.Scores(context)
+	if err != nil {
+		return nil, err
+	}
+
+	next := make([]string, 0, len(scores))
+	for char := range scores {
+		next = append(next, char)
+	}
+	sort.Slice(next, func(i, j int) bool {
+		if scores[next[i]] != scores[next[j]] {
+			return scores[next[i]] > scores[next[j]]
+		}
+		return next[i] < next[j]
+	})
+	if limit >= 0 && len(next) > limit {
+		next = next[:limit]
+	}
+
+	return next, nil
+}
+
+// Reset deletes all the trained n-grams.
+func (m *Model) Reset() error {
+	for _, query := range []string{ResetBigramsQuery, ResetTrigramsQuery} {
+		if _, err := m.db.Exec(query); err != nil {
+			return fmt.Errorf("error deleting n-grams. %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addScores adds the weighted probabilities of the next characters after prev.
+func (m *Model) addScores(scores map[string]float64, query, prev string, weight float64) error {
+	rows, err := m.db.Query(query, prev)
+	if err != nil {
+		return fmt.Errorf("error looking up n-grams of '%s'. %w", prev, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	total := 0
+	for rows.Next() {
+		var (
+			next  string
+			count int
+		)
+		if err := rows.Scan(&next, &count); err != nil {
+			return fmt.Errorf("error reading n-grams of '%s'. %w", prev, err)
+		}
+		counts[next] = count
+		total += count
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading n-grams of '%s'. %w", prev, err)
+	}
+
+	for next, count := range counts {
+		scores[next] += weight * float64(count) / float64(total)
+	}
+
+	return nil
+}
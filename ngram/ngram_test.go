@@ -0,0 +1,68 @@
+package ngram_test
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/antonyho/go-congkit/ngram"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openModel(t *testing.T) *ngram.Model {
+	t.Helper()
+	model, err := ngram.Open(path.Join(t.TempDir(), "ngram.db"))
+	require.NoError(t, err, "failed opening n-gram model")
+	t.Cleanup(func() { model.Close() })
+
+	return model
+}
+
+func TestModelTrain(t *testing.T) {
+	model := openModel(t)
+	require.NoError(t, model.Train(strings.NewReader("香港人。香港人、香味\n香蕉")))
+
+	next, err := model.Next("香", -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"港", "味", "蕉"}, next)
+
+	scores, err := model.Scores("香")
+	require.NoError(t, err)
+	assert.InDelta(t, ngram.BigramWeight*2/4, scores["港"], 1e-9)
+
+	next, err = model.Next("香港", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"人"}, next)
+	scores, err = model.Scores("香港")
+	require.NoError(t, err)
+	assert.InDelta(t, ngram.TrigramWeight+ngram.BigramWeight, scores["人"], 1e-9)
+
+	next, err = model.Next("人", -1)
+	require.NoError(t, err)
+	assert.Empty(t, next, "punctuation breaks the context")
+}
+
+func TestModelTrainAccumulates(t *testing.T) {
+	model := openModel(t)
+	require.NoError(t, model.Train(strings.NewReader("香味")))
+	require.NoError(t, model.Train(strings.NewReader("香港 香港")))
+
+	next, err := model.Next("我香", -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"港", "味"}, next, "the last character backs off the unseen trigram")
+}
+
+func TestModelReset(t *testing.T) {
+	model := openModel(t)
+	require.NoError(t, model.Train(strings.NewReader("香港")))
+	require.NoError(t, model.Reset())
+
+	next, err := model.Next("香", -1)
+	require.NoError(t, err)
+	assert.Empty(t, next)
+
+	next, err = model.Next("", -1)
+	require.NoError(t, err)
+	assert.Empty(t, next)
+}
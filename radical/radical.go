@@ -0,0 +1,165 @@
+// Package radical maps the Congkit keys 'a' to 'z' to their radicals.
+package radical
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnknownGlyph is returned on normalising a code with a non-radical glyph.
+var ErrUnknownGlyph = errors.New("radical: unknown glyph")
+
+// Wildcard is the key standing for any one radical in a code, e.g. "h*i".
+// It is rendered as the WildcardGlyph.
+const (
+	Wildcard      = '*'
+	WildcardGlyph = '＊'
+)
+
+// Radical is a Congkit radical assigned to a key.
+type Radical struct {
+	Key   rune   // Latin key of the radical, 'a' to 'z'
+	Glyph rune   // Chinese glyph of the radical
+	Name  string // English name of the radical
+}
+
+// Radicals of the keys 'a' to 'z' in order.
+var Radicals = [26]Radical{
+	{'a', '日', "Sun"},
+	{'b', '月', "Moon"},
+	{'c', '金', "Gold"},
+	{'d', '木', "Wood"},
+	{'e', '水', "Water"},
+	{'f', '火', "Fire"},
+	{'g', '土', "Earth"},
+	{'h', '竹', "Bamboo"},
+	{'i', '戈', "Weapon"},
+	{'j', '十', "Ten"},
+	{'k', '大', "Big"},
+	{'l', '中', "Centre"},
+	{'m', '一', "One"},
+	{'n', '弓', "Bow"},
+	{'o', '人', "Person"},
+	{'p', '心', "Heart"},
+	{'q', '手', "Hand"},
+	{'r', '口', "Mouth"},
+	{'s', '尸', "Corpse"},
+	{'t', '廿', "Twenty"},
+	{'u', '山', "Mountain"},
+	{'v', '女', "Woman"},
+	{'w', '田', "Field"},
+	{'x', '難', "Difficult"},
+	{'y', '卜', "Divination"},
+	{'z', '重', "Collision"},
+}
+
+// Of returns the radical of the key.
+// The key may be in upper case.
+func Of(key rune) (Radical, bool) {
+	if key >= 'A' && key <= 'Z' {
+		key += 'a' - 'A'
+	}
+	if key < 'a' || key > 'z' {
+		return Radical{}, false
+	}
+
+	return Radicals[key-'a'], true
+}
+
+// Glyph returns the Chinese glyph of the radical of the key.
+func Glyph(key rune) (rune, bool) {
+	r, ok := Of(key)
+
+	return r.Glyph, ok
+}
+
+// Render renders the code as radical glyphs, e.g. "hqi" as "竹手戈"
+// and "h*i" as "竹＊戈".
+// Other characters which are not keys of radicals are kept as they are.
+func Render(code string) string {
+	var b strings.Builder
+	for _, key := range code {
+		if glyph, ok := Glyph(key); ok {
+			b.WriteRune(glyph)
+		} else if key == Wildcard {
+			b.WriteRune(WildcardGlyph)
+		} else {
+			b.WriteRune(key)
+		}
+	}
+
+	return b.String()
+}
+
+// Names lists the English names of the radicals of the code,
+// e.g. "hqi" as ["Bamboo", "Hand", "Weapon"].
+// Characters which are not keys of radicals are kept as they are.
+func Names(code string) []string {
+	names := make([]string, 0, len(code))
+	for _, key := range code {
+		if r, ok := Of(key); ok {
+			names = append(names, r.Name)
+		} else {
+			names = append(names, string(key))
+		}
+	}
+
+	return names
+}
+
+// Annotate renders the code followed by its radical glyphs
+// for reverse lookup output, e.g. "hqi" as "hqi (竹手戈)".
+func Annotate(code string) string {
+	return code + " (" + Render(code) + ")"
+}
+
+// glyphKeys maps the radical glyphs to their keys.
+var glyphKeys = func() map[rune]rune {
+	keys := make(map[rune]rune, len(Radicals))
+	for _, r := range Radicals {
+		keys[r.Glyph] = r.Key
+	}
+
+	return keys
+}()
+
+// Key returns the key of the radical glyph.
+func Key(glyph rune) (rune, bool) {
+	key, ok := glyphKeys[glyph]
+
+	return key, ok
+}
+
+// Normalize converts a code written in radical glyphs, full-width or
+// upper case keys, or a mix of them, into the keys 'a' to 'z',
+// e.g. "竹手戈", "HQI" and "竹qｉ" all as "hqi", and "竹＊戈" as "h*i".
+// Other ASCII characters are kept for the punctuation codes.
+// ErrUnknownGlyph is returned on any other character.
+func Normalize(code string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(code))
+	for _, r := range code {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			b.WriteRune(r + 'a' - 'A')
+		case r >= 'Ａ' && r <= 'Ｚ':
+			b.WriteRune(r - 'Ａ' + 'a')
+		case r >= 'ａ' && r <= 'ｚ':
+			b.WriteRune(r - 'ａ' + 'a')
+		case r < utf8.RuneSelf:
+			b.WriteRune(r)
+		case r == WildcardGlyph:
+			b.WriteRune(Wildcard)
+		default:
+			key, ok := Key(r)
+			if !ok {
+				return "", fmt.Errorf("%w '%c' in code '%s'", ErrUnknownGlyph, r, code)
+			}
+			b.WriteRune(key)
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,86 @@
+package radical_test
+
+import (
+	"testing"
+
+	"github.com/antonyho/go-congkit/radical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOf(t *testing.T) {
+	r, ok := radical.Of('a')
+	assert.True(t, ok)
+	assert.Equal(t, radical.Radical{Key: 'a', Glyph: '日', Name: "Sun"}, r)
+
+	r, ok = radical.Of('Z')
+	assert.True(t, ok)
+	assert.Equal(t, '重', r.Glyph)
+
+	_, ok = radical.Of('1')
+	assert.False(t, ok)
+}
+
+func TestRadicalsInKeyOrder(t *testing.T) {
+	const glyphs = "日月金木水火土竹戈十大中一弓人心手口尸廿山女田難卜重"
+
+	for i, glyph := range []rune(glyphs) {
+		assert.Equal(t, 'a'+rune(i), radical.Radicals[i].Key)
+		assert.Equal(t, glyph, radical.Radicals[i].Glyph)
+	}
+}
+
+func TestRender(t *testing.T) {
+	assert.Equal(t, "竹手戈", radical.Render("hqi"))
+	assert.Equal(t, "重難日木", radical.Render("zxad"))
+	assert.Equal(t, "人戈?", radical.Render("oi?"))
+	assert.Equal(t, "竹＊戈", radical.Render("h*i"))
+	assert.Equal(t, "", radical.Render(""))
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{"Bamboo", "Hand", "Weapon"}, radical.Names("hqi"))
+}
+
+func TestAnnotate(t *testing.T) {
+	assert.Equal(t, "hqi (竹手戈)", radical.Annotate("hqi"))
+}
+
+func TestKey(t *testing.T) {
+	key, ok := radical.Key('竹')
+	assert.True(t, ok)
+	assert.Equal(t, 'h', key)
+
+	_, ok = radical.Key('我')
+	assert.False(t, ok)
+}
+
+func TestNormalize(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{"keys", "hqi", "hqi"},
+		{"glyphs", "竹手戈", "hqi"},
+		{"mixed", "竹q戈", "hqi"},
+		{"upper case", "HQI", "hqi"},
+		{"full-width", "ｈＱｉ", "hqi"},
+		{"punctuation", ",", ","},
+		{"wildcard", "竹＊戈", "h*i"},
+		{"empty", "", ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			code, err := radical.Normalize(testCase.code)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, code)
+		})
+	}
+}
+
+func TestNormalizeUnknownGlyph(t *testing.T) {
+	_, err := radical.Normalize("竹我")
+	assert.ErrorIs(t, err, radical.ErrUnknownGlyph)
+}
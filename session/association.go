@@ -0,0 +1,61 @@
+package session
+
+import (
+	"unicode/utf8"
+
+	"github.com/antonyho/go-congkit/engine"
+)
+
+// Associator lists the text following a prefix in the phrases.
+// *engine.Engine is an Associator.
+type Associator interface {
+	Associate(prefix string) ([]string, error)
+}
+
+var _ Associator = (*engine.Engine)(nil)
+
+// WithAssociation lists the associated phrases as the candidates after a commit,
+// e.g. "港", "味" and "蕉" after committing "香". It needs an Associator encoder.
+func WithAssociation() Option {
+	return func(s *Session) {
+		s.association = true
+	}
+}
+
+// Associating is the committed text of the listed associated phrases,
+// or an empty string if no associated phrases are listed.
+func (s *Session) Associating() string {
+	return s.associating
+}
+
+// associate lists the associated phrases of the committed text.
+// The phrases following the last character are listed
+// if there is no phrase following the whole committed text.
+func (s *Session) associate(committed string) error {
+	associator, ok := s.encoder.(Associator)
+	if !s.association || !ok {
+		return nil
+	}
+
+	follows, err := associator.Associate(committed)
+	if err != nil {
+		return err
+	}
+	if len(follows) == 0 {
+		_, size := utf8.DecodeLastRuneInString(committed)
+		if size < len(committed) {
+			committed = committed[len(committed)-size:]
+			if follows, err = associator.Associate(committed); err != nil {
+				return err
+			}
+		}
+	}
+	if len(follows) == 0 {
+		return nil
+	}
+
+	s.associating = committed
+	s.list.Set(follows)
+
+	return nil
+}
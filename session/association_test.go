@@ -0,0 +1,115 @@
+package session_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAssociator is a fakeEncoder which also lists associated phrases.
+type fakeAssociator struct {
+	*fakeEncoder
+	phrases []string
+	err     error
+}
+
+func (f *fakeAssociator) Associate(prefix string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	follows := make([]string, 0)
+	for _, phrase := range f.phrases {
+		if len(phrase) > len(prefix) && strings.HasPrefix(phrase, prefix) {
+			follows = append(follows, strings.TrimPrefix(phrase, prefix))
+		}
+	}
+
+	return follows, nil
+}
+
+func newFakeAssociator() *fakeAssociator {
+	encoder := newFakeEncoder()
+	encoder.table["a"] = []rune{'日', '香'}
+
+	return &fakeAssociator{
+		fakeEncoder: encoder,
+		phrases:     []string{"香港", "香味", "香蕉", "香港人", "人民", "我們"},
+	}
+}
+
+func TestSessionAssociation(t *testing.T) {
+	s := session.New(newFakeAssociator(), session.WithAssociation())
+
+	events := press(t, s, 'a', '2')
+	assert.Equal(t, "香", commits(events))
+	assert.Equal(t, "香", s.Associating())
+	assert.Equal(t, []string{"港", "味", "蕉", "港人"}, s.Candidates())
+	assert.Empty(t, s.Preedit())
+
+	events = press(t, s, '1')
+	assert.Equal(t, "港", commits(events))
+	assert.Equal(t, "香港", s.Associating())
+	assert.Equal(t, []string{"人"}, s.Candidates())
+
+	events = press(t, s, session.KeySpace)
+	assert.Equal(t, []session.Event{
+		{Type: session.EventCandidatesChanged},
+		{Type: session.EventPassthrough, Text: " ", Key: session.KeySpace},
+	}, events, "other keys dismiss the associated phrases")
+	assert.Empty(t, s.Associating())
+	assert.Empty(t, s.Candidates())
+}
+
+func TestSessionAssociationFallbackToLastCharacter(t *testing.T) {
+	s := session.New(newFakeAssociator(), session.WithAssociation())
+
+	events := press(t, s, 'a', '2', '4')
+	assert.Equal(t, "香港人", commits(events))
+	assert.Equal(t, "人", s.Associating(), "no phrase follows 香港人")
+	assert.Equal(t, []string{"民"}, s.Candidates())
+}
+
+func TestSessionAssociationEscape(t *testing.T) {
+	s := session.New(newFakeAssociator(), session.WithAssociation())
+
+	press(t, s, 'a', '2')
+	events := press(t, s, session.KeyEscape)
+	assert.Equal(t, []session.Event{{Type: session.EventCandidatesChanged}}, events)
+	assert.Empty(t, s.Associating())
+}
+
+func TestSessionAssociationTyping(t *testing.T) {
+	s := session.New(newFakeAssociator(), session.WithAssociation())
+
+	press(t, s, 'h', 'q', 'i', session.KeySpace)
+	assert.Equal(t, "我", s.Associating())
+
+	press(t, s, 'o')
+	assert.Empty(t, s.Associating())
+	assert.Equal(t, "o", s.Preedit())
+	assert.Equal(t, []string{"人"}, s.Candidates())
+}
+
+func TestSessionAssociationOff(t *testing.T) {
+	s := session.New(newFakeAssociator())
+
+	press(t, s, 'a', '2')
+	assert.Empty(t, s.Associating())
+	assert.Empty(t, s.Candidates())
+}
+
+func TestSessionAssociationError(t *testing.T) {
+	associator := newFakeAssociator()
+	associator.err = errors.New("associate failed")
+	s := session.New(associator, session.WithAssociation())
+
+	press(t, s, 'a')
+	events, err := s.Press('2')
+	require.Error(t, err)
+	assert.Equal(t, "香", commits(events), "committed text is returned with the error")
+	assert.Empty(t, s.Associating())
+}
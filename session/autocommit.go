@@ -0,0 +1,67 @@
+package session
+
+import (
+	"slices"
+
+	"github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// AutoCommit is the rule of committing a candidate without a selection key.
+// The rules can be combined, e.g. AutoCommitMaxLength | AutoCommitUnique.
+type AutoCommit int
+
+// AutoCommitOff never commits automatically.
+const AutoCommitOff AutoCommit = 0
+
+const (
+	// AutoCommitMaxLength commits the first candidate when the preedit
+	// reaches the maximum number of radicals.
+	AutoCommitMaxLength AutoCommit = 1 << iota
+	// AutoCommitUnique commits the only candidate when no longer code
+	// starts with the preedit. It needs an Extender encoder.
+	AutoCommitUnique
+)
+
+// Extender tells whether a code can be extended into longer codes.
+// *engine.Engine is an Extender.
+type Extender interface {
+	Extendable(radicals string) (bool, error)
+}
+
+var _ Extender = (*engine.Engine)(nil)
+
+// WithAutoCommit sets the auto-commit rule. The default is AutoCommitOff.
+func WithAutoCommit(rule AutoCommit) Option {
+	return func(s *Session) {
+		s.autoCommit = rule
+	}
+}
+
+// shouldAutoCommit checks the auto-commit rule against the current composition.
+// A preedit with a wildcard is never committed automatically,
+// as the user is choosing among the matches of the wildcard.
+func (s *Session) shouldAutoCommit() (bool, error) {
+	candidates := s.Candidates()
+	if len(candidates) == 0 || slices.Contains(s.preedit, radical.Wildcard) {
+		return false, nil
+	}
+
+	if s.autoCommit&AutoCommitMaxLength != 0 && len(s.preedit) >= engine.MaxRadicals {
+		return true, nil
+	}
+
+	if s.autoCommit&AutoCommitUnique != 0 && len(candidates) == 1 {
+		extender, ok := s.encoder.(Extender)
+		if !ok {
+			return false, nil
+		}
+		extendable, err := extender.Extendable(string(s.preedit))
+		if err != nil {
+			return false, err
+		}
+		return !extendable, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,104 @@
+package session_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeExtender is a fakeEncoder which also tells the extendable codes.
+type fakeExtender struct {
+	*fakeEncoder
+	err error
+}
+
+func (f *fakeExtender) Extendable(radicals string) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	for code := range f.table {
+		if len(code) > len(radicals) && strings.HasPrefix(code, radicals) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func TestSessionAutoCommitOff(t *testing.T) {
+	s := session.New(&fakeExtender{fakeEncoder: newFakeEncoder()})
+
+	events := press(t, s, 'y', 'k', 'm', 'h', 'm')
+	assert.Empty(t, commits(events))
+	assert.Equal(t, "ykmhm", s.Preedit())
+}
+
+func TestSessionAutoCommitMaxLength(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithAutoCommit(session.AutoCommitMaxLength))
+
+	events := press(t, s, 'y', 'k', 'm', 'h', 'm')
+	assert.Equal(t, "產", commits(events))
+	assert.Empty(t, s.Preedit())
+
+	events = press(t, s, 'o', 'i', 'a', 'r')
+	assert.Empty(t, commits(events), "unique match is not committed by the max length rule")
+}
+
+func TestSessionAutoCommitUnique(t *testing.T) {
+	s := session.New(
+		&fakeExtender{fakeEncoder: newFakeEncoder()},
+		session.WithAutoCommit(session.AutoCommitUnique),
+	)
+
+	events := press(t, s, 'o', 'i')
+	assert.Empty(t, commits(events), "unique match with longer codes")
+
+	events = press(t, s, 'a', 'r')
+	assert.Equal(t, "倉", commits(events))
+	assert.Empty(t, s.Preedit())
+
+	events = press(t, s, 'h', 'q', 'i')
+	assert.Empty(t, commits(events), "multiple matches")
+}
+
+func TestSessionAutoCommitWildcard(t *testing.T) {
+	encoder := newFakeEncoder()
+	encoder.table["oi*r"] = []rune{'倉'}
+	encoder.table["ykm*m"] = []rune{'產'}
+	s := session.New(
+		&fakeExtender{fakeEncoder: encoder},
+		session.WithAutoCommit(session.AutoCommitUnique|session.AutoCommitMaxLength),
+		session.WithWildcardKey('?'),
+	)
+
+	events := press(t, s, 'o', 'i', '?', 'r')
+	assert.Empty(t, commits(events), "a unique wildcard match is not committed")
+	assert.Equal(t, "oi*r", s.Preedit())
+
+	press(t, s, session.KeyEscape)
+	events = press(t, s, 'y', 'k', 'm', '?', 'm')
+	assert.Empty(t, commits(events), "a wildcard preedit of the max length is not committed")
+	assert.Equal(t, "ykm*m", s.Preedit())
+}
+
+func TestSessionAutoCommitUniqueWithoutExtender(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithAutoCommit(session.AutoCommitUnique))
+
+	events := press(t, s, 'o', 'i', 'a', 'r')
+	assert.Empty(t, commits(events))
+}
+
+func TestSessionAutoCommitError(t *testing.T) {
+	extender := &fakeExtender{fakeEncoder: newFakeEncoder()}
+	s := session.New(extender, session.WithAutoCommit(session.AutoCommitUnique))
+	press(t, s, 'o', 'i', 'a')
+
+	extender.err = errors.New("extendable failed")
+	_, err := s.Press('r')
+	assert.Error(t, err)
+	assert.Equal(t, "oia", s.Preedit())
+	assert.Equal(t, []string{"㑁"}, s.Candidates())
+}
@@ -0,0 +1,91 @@
+package session
+
+// Page is a page of candidates for rendering the candidate window.
+type Page struct {
+	Index      int      // Index of the page, starting from 0
+	Count      int      // Number of pages
+	Candidates []string // Candidates on the page
+	Labels     []Key    // Selection keys of the candidates on the page
+}
+
+// CandidateList splits the candidates into pages.
+type CandidateList struct {
+	candidates []string
+	pageSize   int
+	page       int
+}
+
+// NewCandidateList creates a candidate list with the page size.
+// Page size less than 1 is taken as 1.
+func NewCandidateList(pageSize int) *CandidateList {
+	return &CandidateList{pageSize: max(pageSize, 1)}
+}
+
+// Set replaces the candidates and turns to the first page.
+func (l *CandidateList) Set(candidates []string) {
+	l.candidates = candidates
+	l.page = 0
+}
+
+// All lists all the candidates.
+func (l *CandidateList) All() []string {
+	return l.candidates
+}
+
+// Len is the number of all candidates.
+func (l *CandidateList) Len() int {
+	return len(l.candidates)
+}
+
+// PageSize is the maximum number of candidates on a page.
+func (l *CandidateList) PageSize() int {
+	return l.pageSize
+}
+
+// Page returns the current page.
+func (l *CandidateList) Page() Page {
+	start := l.page * l.pageSize
+	end := min(start+l.pageSize, len(l.candidates))
+
+	return Page{
+		Index:      l.page,
+		Count:      l.pageCount(),
+		Candidates: l.candidates[start:end],
+	}
+}
+
+// NextPage turns to the next page.
+// It returns false if it is already the last page.
+func (l *CandidateList) NextPage() bool {
+	if l.page+1 >= l.pageCount() {
+		return false
+	}
+	l.page++
+
+	return true
+}
+
+// PreviousPage turns to the previous page.
+// It returns false if it is already the first page.
+func (l *CandidateList) PreviousPage() bool {
+	if l.page == 0 {
+		return false
+	}
+	l.page--
+
+	return true
+}
+
+// At returns the candidate at the index of the current page.
+func (l *CandidateList) At(index int) (string, bool) {
+	candidates := l.Page().Candidates
+	if index < 0 || index >= len(candidates) {
+		return "", false
+	}
+
+	return candidates[index], true
+}
+
+func (l *CandidateList) pageCount() int {
+	return (len(l.candidates) + l.pageSize - 1) / l.pageSize
+}
@@ -0,0 +1,45 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandidateList(t *testing.T) {
+	list := session.NewCandidateList(2)
+	list.Set([]string{"一", "二", "三", "四", "五"})
+
+	assert.Equal(t, 5, list.Len())
+	assert.Equal(t, session.Page{Index: 0, Count: 3, Candidates: []string{"一", "二"}}, list.Page())
+
+	assert.False(t, list.PreviousPage())
+	assert.True(t, list.NextPage())
+	assert.True(t, list.NextPage())
+	assert.False(t, list.NextPage())
+	assert.Equal(t, session.Page{Index: 2, Count: 3, Candidates: []string{"五"}}, list.Page())
+
+	candidate, ok := list.At(0)
+	assert.True(t, ok)
+	assert.Equal(t, "五", candidate)
+	_, ok = list.At(1)
+	assert.False(t, ok)
+
+	assert.True(t, list.PreviousPage())
+	assert.Equal(t, 1, list.Page().Index)
+
+	list.Set([]string{"六"})
+	assert.Equal(t, session.Page{Index: 0, Count: 1, Candidates: []string{"六"}}, list.Page())
+}
+
+func TestEmptyCandidateList(t *testing.T) {
+	list := session.NewCandidateList(0)
+
+	assert.Equal(t, 1, list.PageSize())
+	assert.Equal(t, 0, list.Page().Count)
+	assert.Empty(t, list.Page().Candidates)
+	assert.False(t, list.NextPage())
+	_, ok := list.At(0)
+	assert.False(t, ok)
+}
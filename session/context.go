@@ -0,0 +1,27 @@
+package session
+
+import "github.com/antonyho/go-congkit/engine"
+
+// MaxContext is the number of the last committed characters kept as the context.
+const MaxContext = 2
+
+// ContextEncoder lists the candidates of the radicals ranked by the context,
+// the characters committed just before. The session prefers a ContextEncoder
+// to a CandidateEncoder. *engine.Engine is a ContextEncoder.
+type ContextEncoder interface {
+	CandidatesAfter(radicals, context string) ([]string, error)
+}
+
+var _ ContextEncoder = (*engine.Engine)(nil)
+
+// Context is the last characters committed from the candidates.
+// Any other committed or passed through text clears the context.
+func (s *Session) Context() string {
+	return s.context
+}
+
+// remember keeps the last characters of the committed text as the context.
+func (s *Session) remember(text string) {
+	context := []rune(s.context + text)
+	s.context = string(context[max(len(context)-MaxContext, 0):])
+}
@@ -0,0 +1,60 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeContextEncoder is a fakeEncoder which lists the candidates
+// after the context first.
+type fakeContextEncoder struct {
+	*fakeEncoder
+	follows  map[string]string
+	contexts []string
+}
+
+func (f *fakeContextEncoder) CandidatesAfter(radicals, context string) ([]string, error) {
+	f.contexts = append(f.contexts, context)
+	chars, err := f.Encode(radicals)
+	candidates := make([]string, 0, len(chars))
+	for _, char := range chars {
+		if string(char) == f.follows[context] {
+			candidates = append([]string{string(char)}, candidates...)
+		} else {
+			candidates = append(candidates, string(char))
+		}
+	}
+
+	return candidates, err
+}
+
+func TestSessionContext(t *testing.T) {
+	encoder := &fakeContextEncoder{
+		fakeEncoder: newFakeEncoder(),
+		follows:     map[string]string{"人倉": "牫"},
+	}
+	s := session.New(encoder)
+
+	press(t, s, 'o', '1', 'o', 'i', 'a', 'r', '1')
+	assert.Equal(t, "人倉", s.Context())
+
+	press(t, s, 'h', 'q', 'i')
+	assert.Equal(t, []string{"牫", "我", "𥫻"}, s.Candidates(), "the candidates are ranked by the context")
+	assert.Equal(t, "人倉", encoder.contexts[len(encoder.contexts)-1])
+
+	press(t, s, '1')
+	assert.Equal(t, "倉牫", s.Context(), "the context keeps the last characters")
+
+	press(t, s, '!')
+	assert.Empty(t, s.Context(), "other text clears the context")
+}
+
+func TestSessionContextUndo(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	press(t, s, 'o', '1', 'o', 'i', 'a', 'r', '1')
+	undo(t, s)
+	assert.Equal(t, "人", s.Context())
+}
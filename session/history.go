@@ -0,0 +1,116 @@
+package session
+
+// DefaultHistorySize is the default number of commits kept in the history.
+const DefaultHistorySize = 16
+
+// Commit is a candidate committed by the session.
+type Commit struct {
+	Text     string // The committed candidate
+	Radicals string // The preedit producing the candidate, empty for associated phrases
+
+	candidates  [][]string // The candidates stack of the composition, or the associated phrases
+	associating string     // The committed text of the associated phrases
+	context     string     // The context before the commit
+}
+
+// WithHistorySize sets the number of commits kept in the history.
+// A size of 0 turns off the history and Undo.
+func WithHistorySize(size int) Option {
+	return func(s *Session) {
+		s.historySize = max(size, 0)
+	}
+}
+
+// WithUndoKey sets the key undoing the last commit. There is no undo key by default.
+func WithUndoKey(key Key) Option {
+	return func(s *Session) {
+		s.undoKey = key
+	}
+}
+
+// History lists the kept commits, the oldest first.
+func (s *Session) History() []Commit {
+	history := make([]Commit, len(s.history))
+	copy(history, s.history)
+
+	return history
+}
+
+// Undo retracts the last commit and restores its preedit and candidates,
+// so that another candidate can be selected. The composition in progress is dropped.
+// The selection learned on the commit is reversed if the encoder is a Learner.
+// Only the commits which are not followed by other text can be undone,
+// e.g. a commit followed by a punctuation or a passed through key cannot be undone.
+// It returns no events if there is nothing to undo. The commit is retracted
+// even if the learned selection cannot be reversed.
+// The events and the error are also delivered to the hooks.
+func (s *Session) Undo() ([]Event, error) {
+	events, err := s.undo()
+	s.notify(events, err)
+
+	return events, err
+}
+
+// undo retracts the last commit.
+func (s *Session) undo() ([]Event, error) {
+	if s.undoable == 0 {
+		return nil, nil
+	}
+
+	last := s.history[len(s.history)-1]
+	s.history[len(s.history)-1] = Commit{}
+	s.history = s.history[:len(s.history)-1]
+	s.undoable--
+
+	s.reset()
+	s.preedit = append(s.preedit, last.Radicals...)
+	s.cursor = len(s.preedit)
+	s.associating = last.associating
+	s.context = last.context
+	if s.associating == "" {
+		s.candidates = append(s.candidates, last.candidates...)
+	}
+	if len(last.candidates) > 0 {
+		s.list.Set(last.candidates[len(last.candidates)-1])
+	}
+
+	return []Event{
+		{Type: EventRetract, Text: last.Text},
+		{Type: EventPreeditChanged},
+		{Type: EventCandidatesChanged},
+	}, s.unlearn(last.Radicals, last.Text)
+}
+
+// record keeps the commit of the candidate in the history
+// before the composition is cleared.
+func (s *Session) record(candidate string) {
+	if s.historySize == 0 {
+		return
+	}
+
+	commit := Commit{
+		Text:        candidate,
+		Radicals:    string(s.preedit),
+		candidates:  append([][]string(nil), s.candidates...),
+		associating: s.associating,
+		context:     s.context,
+	}
+	if s.associating != "" {
+		commit.candidates = [][]string{s.list.All()}
+	}
+	if len(s.history) == s.historySize {
+		// Shift in place so that the dropped commit is freed and the history does not grow.
+		copy(s.history, s.history[1:])
+		s.history[len(s.history)-1] = commit
+	} else {
+		s.history = append(s.history, commit)
+	}
+	s.undoable = min(s.undoable+1, len(s.history))
+}
+
+// seal stops the earlier commits from being undone and clears the context
+// after committing or passing through other text.
+func (s *Session) seal() {
+	s.undoable = 0
+	s.context = ""
+}
@@ -0,0 +1,140 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// undo undoes the last commit without error.
+func undo(t *testing.T, s *session.Session) []session.Event {
+	t.Helper()
+	events, err := s.Undo()
+	require.NoError(t, err)
+
+	return events
+}
+
+func TestSessionUndo(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	events := press(t, s, 'h', 'q', 'i', '2')
+	assert.Equal(t, "牫", commits(events))
+
+	events = undo(t, s)
+	assert.Equal(t, []session.Event{
+		{Type: session.EventRetract, Text: "牫"},
+		{Type: session.EventPreeditChanged},
+		{Type: session.EventCandidatesChanged},
+	}, events)
+	assert.Equal(t, "hqi", s.Preedit())
+	assert.Equal(t, []string{"我", "牫", "𥫻"}, s.Candidates())
+	assert.Empty(t, s.History())
+
+	press(t, s, session.KeyBackspace)
+	assert.Equal(t, "hq", s.Preedit(), "the radicals of the undone commit can be deleted")
+	assert.Equal(t, []string{"秉", "乎"}, s.Candidates())
+
+	events = press(t, s, 'i', '1')
+	assert.Equal(t, "我", commits(events))
+	assert.Equal(t, []session.Commit{{Text: "我", Radicals: "hqi"}}, historyTexts(s))
+}
+
+func TestSessionUndoMultiple(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	press(t, s, 'o', 'i', 'a', 'r', '1', 'h', 'q', 'i', '1')
+	assert.Equal(t, []session.Commit{
+		{Text: "倉", Radicals: "oiar"},
+		{Text: "我", Radicals: "hqi"},
+	}, historyTexts(s))
+
+	assert.Equal(t, "我", undo(t, s)[0].Text)
+	assert.Equal(t, "倉", undo(t, s)[0].Text, "the restored composition is dropped")
+	assert.Equal(t, "oiar", s.Preedit())
+	assert.Nil(t, undo(t, s))
+}
+
+func TestSessionUndoSealed(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	press(t, s, 'o', 'i', 'a', 'r', '1', '!')
+	assert.Nil(t, undo(t, s), "a commit followed by other text cannot be undone")
+	assert.Len(t, s.History(), 1)
+
+	press(t, s, 'h', 'q', 'i', session.KeySpace)
+	assert.Equal(t, "我", undo(t, s)[0].Text)
+	assert.Nil(t, undo(t, s))
+}
+
+func TestSessionUndoKey(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithUndoKey('`'))
+
+	press(t, s, 'h', 'q', 'i', '2')
+	events := press(t, s, '`')
+	assert.Equal(t, session.EventRetract, events[0].Type)
+	assert.Equal(t, "hqi", s.Preedit())
+
+	events = press(t, s, '1')
+	assert.Equal(t, "我", commits(events))
+}
+
+func TestSessionUndoAssociation(t *testing.T) {
+	s := session.New(newFakeAssociator(), session.WithAssociation())
+
+	press(t, s, 'a', '2', '1')
+	assert.Equal(t, "香港", s.Associating())
+
+	events := undo(t, s)
+	assert.Equal(t, "港", events[0].Text)
+	assert.Equal(t, "香", s.Associating())
+	assert.Empty(t, s.Preedit())
+	assert.Equal(t, []string{"港", "味", "蕉", "港人"}, s.Candidates())
+
+	events = press(t, s, '2')
+	assert.Equal(t, "味", commits(events))
+}
+
+func TestSessionHistorySize(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithHistorySize(1))
+	press(t, s, 'o', 'i', 'a', 'r', '1', 'h', 'q', 'i', '1')
+	assert.Equal(t, []session.Commit{{Text: "我", Radicals: "hqi"}}, historyTexts(s))
+	assert.NotNil(t, undo(t, s))
+	assert.Nil(t, undo(t, s))
+
+	s = session.New(newFakeEncoder(), session.WithHistorySize(0))
+	press(t, s, 'o', 'i', 'a', 'r', '1')
+	assert.Empty(t, s.History())
+	assert.Nil(t, undo(t, s))
+}
+
+func TestSessionHistoryDropsOldest(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithHistorySize(2))
+	for i := 0; i < 100; i++ {
+		press(t, s, 'o', 'i', 'a', 'r', '1')
+	}
+	press(t, s, 'h', 'q', 'i', '1')
+	assert.Equal(t, []session.Commit{
+		{Text: "倉", Radicals: "oiar"},
+		{Text: "我", Radicals: "hqi"},
+	}, historyTexts(s))
+
+	assert.Equal(t, "我", undo(t, s)[0].Text)
+	press(t, s, '2')
+	assert.Equal(t, []session.Commit{
+		{Text: "倉", Radicals: "oiar"},
+		{Text: "牫", Radicals: "hqi"},
+	}, historyTexts(s))
+}
+
+// historyTexts lists the history without the unexported composition state.
+func historyTexts(s *session.Session) []session.Commit {
+	history := make([]session.Commit, 0)
+	for _, commit := range s.History() {
+		history = append(history, session.Commit{Text: commit.Text, Radicals: commit.Radicals})
+	}
+
+	return history
+}
@@ -0,0 +1,90 @@
+package session
+
+// Hooks are the functions called on the session events.
+// The hooks are called synchronously before the methods changing the session
+// return, e.g. Press, SetMode, Undo, Reset and Restore. Any of them can be nil.
+type Hooks struct {
+	OnCommit            func(text string)
+	OnCandidatesChanged func(page Page) // Called once with the current page for all the changes of a key
+	OnModeChanged       func(mode Mode)
+	OnError             func(err error)
+}
+
+// HookEvent is a session event delivered to a hook channel.
+type HookEvent struct {
+	Type EventType // EventCommit, EventCandidatesChanged, EventModeChanged or EventError
+	Text string    // The committed text
+	Page Page      // The candidate page after the changes
+	Mode Mode      // The input mode switched to
+	Err  error     // The error on handling the key
+}
+
+// WithHooks adds the hooks. All the added hooks are called in the order they were added.
+func WithHooks(hooks Hooks) Option {
+	return func(s *Session) {
+		s.hooks = append(s.hooks, hooks)
+	}
+}
+
+// WithHookChannel adds the channel the hook events are sent to.
+// The events are sent without blocking, the events are dropped
+// if the channel is full. The session does not close the channel.
+func WithHookChannel(ch chan<- HookEvent) Option {
+	return func(s *Session) {
+		s.hookChannels = append(s.hookChannels, ch)
+	}
+}
+
+// DroppedHookEvents is the number of the hook events dropped for full channels.
+func (s *Session) DroppedHookEvents() int {
+	return s.dropped
+}
+
+// notify delivers the events and the error to the hooks and the hook channels.
+func (s *Session) notify(events []Event, err error) {
+	if len(s.hooks) == 0 && len(s.hookChannels) == 0 {
+		return
+	}
+
+	candidatesChanged := false
+	for _, event := range events {
+		switch event.Type {
+		case EventCommit:
+			s.deliver(HookEvent{Type: EventCommit, Text: event.Text})
+		case EventCandidatesChanged:
+			candidatesChanged = true
+		case EventModeChanged:
+			s.deliver(HookEvent{Type: EventModeChanged, Mode: s.mode})
+		}
+	}
+	if candidatesChanged {
+		s.deliver(HookEvent{Type: EventCandidatesChanged, Page: s.Page()})
+	}
+	if err != nil {
+		s.deliver(HookEvent{Type: EventError, Err: err})
+	}
+}
+
+// deliver calls the hooks of the event and sends it to the hook channels.
+func (s *Session) deliver(event HookEvent) {
+	for _, hooks := range s.hooks {
+		switch {
+		case event.Type == EventCommit && hooks.OnCommit != nil:
+			hooks.OnCommit(event.Text)
+		case event.Type == EventCandidatesChanged && hooks.OnCandidatesChanged != nil:
+			hooks.OnCandidatesChanged(event.Page)
+		case event.Type == EventModeChanged && hooks.OnModeChanged != nil:
+			hooks.OnModeChanged(event.Mode)
+		case event.Type == EventError && hooks.OnError != nil:
+			hooks.OnError(event.Err)
+		}
+	}
+
+	for _, ch := range s.hookChannels {
+		select {
+		case ch <- event:
+		default:
+			s.dropped++
+		}
+	}
+}
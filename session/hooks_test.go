@@ -0,0 +1,116 @@
+package session_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionHooks(t *testing.T) {
+	var (
+		commits []string
+		pages   []session.Page
+		modes   []session.Mode
+	)
+	s := session.New(newFakeEncoder(), session.WithHooks(session.Hooks{
+		OnCommit:            func(text string) { commits = append(commits, text) },
+		OnCandidatesChanged: func(page session.Page) { pages = append(pages, page) },
+		OnModeChanged:       func(mode session.Mode) { modes = append(modes, mode) },
+	}))
+
+	press(t, s, 'h', 'q')
+	assert.Len(t, pages, 2)
+	assert.Equal(t, []string{"秉", "乎"}, pages[1].Candidates)
+
+	press(t, s, '2', session.KeyShift)
+	assert.Equal(t, []string{"乎"}, commits)
+	assert.Empty(t, pages[2].Candidates)
+	assert.Equal(t, []session.Mode{session.ModeEnglish}, modes)
+
+	s.SetMode(session.ModeCongkit)
+	assert.Equal(t, []session.Mode{session.ModeEnglish, session.ModeCongkit}, modes)
+
+	undo(t, s)
+	assert.Equal(t, []string{"秉", "乎"}, pages[len(pages)-1].Candidates)
+	assert.Len(t, commits, 1, "a retraction is not a commit")
+}
+
+func TestSessionHooksStateChanges(t *testing.T) {
+	var (
+		pages []session.Page
+		modes []session.Mode
+	)
+	s := session.New(newFakeEncoder(), session.WithHooks(session.Hooks{
+		OnCandidatesChanged: func(page session.Page) { pages = append(pages, page) },
+		OnModeChanged:       func(mode session.Mode) { modes = append(modes, mode) },
+	}))
+
+	press(t, s, 'h', 'q')
+	snapshot := s.Snapshot()
+	pages = nil
+	s.Reset()
+	assert.Len(t, pages, 1)
+	assert.Empty(t, pages[0].Candidates)
+	s.Reset()
+	assert.Len(t, pages, 1, "resetting an empty session changes nothing")
+
+	s.SetFullWidthLatin(true)
+	s.SetFullWidthLatin(true)
+	s.SetFullWidthPunctuation(true)
+	assert.Equal(t, []session.Mode{session.ModeCongkit, session.ModeCongkit}, modes)
+
+	modes = nil
+	assert.NoError(t, s.Restore(snapshot))
+	assert.Len(t, pages, 2)
+	assert.Equal(t, []string{"秉", "乎"}, pages[1].Candidates)
+	assert.Equal(t, []session.Mode{session.ModeCongkit}, modes, "the toggles are restored")
+}
+
+func TestSessionHooksError(t *testing.T) {
+	encodeErr := errors.New("encode failed")
+	encoder := newFakeEncoder()
+	encoder.err = encodeErr
+
+	var errs []error
+	s := session.New(encoder,
+		session.WithHooks(session.Hooks{OnError: func(err error) { errs = append(errs, err) }}),
+		session.WithHooks(session.Hooks{OnCommit: func(string) {}}),
+	)
+
+	_, err := s.Press('h')
+	assert.ErrorIs(t, err, encodeErr)
+	assert.Equal(t, []error{err}, errs)
+}
+
+func TestSessionHookChannel(t *testing.T) {
+	ch := make(chan session.HookEvent, 3)
+	s := session.New(newFakeEncoder(), session.WithHookChannel(ch))
+
+	press(t, s, 'o', 'i', 'a', 'r', '1')
+	assert.Equal(t, 3, s.DroppedHookEvents(), "the events are dropped when the channel is full")
+	close(ch)
+
+	events := make([]session.HookEvent, 0)
+	for event := range ch {
+		events = append(events, event)
+	}
+	assert.Len(t, events, 3)
+	assert.Equal(t, session.EventCandidatesChanged, events[0].Type)
+	assert.Equal(t, []string{"人"}, events[0].Page.Candidates)
+}
+
+func TestSessionHookChannelCommit(t *testing.T) {
+	ch := make(chan session.HookEvent, 8)
+	s := session.New(newFakeEncoder(), session.WithHookChannel(ch))
+
+	press(t, s, 'o', 'i', 'a', 'r')
+	for len(ch) > 0 {
+		<-ch
+	}
+	press(t, s, session.KeySpace)
+	assert.Equal(t, session.HookEvent{Type: session.EventCommit, Text: "倉"}, <-ch)
+	assert.Equal(t, session.EventCandidatesChanged, (<-ch).Type)
+	assert.Zero(t, s.DroppedHookEvents())
+}
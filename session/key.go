@@ -0,0 +1,44 @@
+package session
+
+// Key is a key event sent to a session.
+// Printable keys are their own runes, control keys are their ASCII
+// control codes and the keys without a character are negative.
+type Key rune
+
+// Control keys
+const (
+	KeyBackspace Key = 0x08
+	KeyEnter     Key = 0x0d
+	KeyEscape    Key = 0x1b
+	KeySpace     Key = ' '
+	KeyDelete    Key = 0x7f
+)
+
+// Keys without a character
+const (
+	KeyPageUp Key = -1 - iota
+	KeyPageDown
+	KeyShift
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+)
+
+// Default keys of the candidate window
+var (
+	DefaultSelectionKeys    = []Key{'1', '2', '3', '4', '5', '6', '7', '8', '9'}
+	DefaultPreviousPageKeys = []Key{KeyPageUp, '-'}
+	DefaultNextPageKeys     = []Key{KeyPageDown, '='}
+)
+
+// indexOf returns the index of the key in the keys, or -1 if not found.
+func indexOf(keys []Key, key Key) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+
+	return -1
+}
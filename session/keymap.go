@@ -0,0 +1,21 @@
+package session
+
+import "github.com/antonyho/go-congkit/keymap"
+
+// WithKeymap translates the keys typed on another keyboard layout
+// to the QWERTY keys at the same positions for the radical, selection
+// and page keys. Punctuations and passed through keys are kept as typed.
+func WithKeymap(k *keymap.Keymap) Option {
+	return func(s *Session) {
+		s.keymap = k
+	}
+}
+
+// mapKey translates the typed key to the QWERTY key of the keymap.
+func (s *Session) mapKey(key Key) Key {
+	if s.keymap == nil || key < 0 {
+		return key
+	}
+
+	return Key(s.keymap.Key(rune(key)))
+}
@@ -0,0 +1,33 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/antonyho/go-congkit/keymap"
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionKeymap(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithKeymap(keymap.Dvorak))
+
+	press(t, s, 'd', '\'', 'c')
+	assert.Equal(t, "hqi", s.Preedit())
+	assert.Equal(t, []string{"我", "牫", "𥫻"}, s.Candidates())
+
+	events := press(t, s, '2')
+	assert.Equal(t, "牫", commits(events))
+
+	events = press(t, s, 'w')
+	assert.Equal(t, []session.Event{{Type: session.EventPassthrough, Text: "w", Key: 'w'}}, events,
+		"keys which are not radicals are passed through as typed")
+}
+
+func TestSessionKeymapSelection(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithKeymap(keymap.AZERTY))
+
+	press(t, s, 'h', 'a', 'i')
+	assert.Equal(t, "hqi", s.Preedit())
+	events := press(t, s, 'é')
+	assert.Equal(t, "牫", commits(events), "the unshifted number row selects on AZERTY")
+}
@@ -0,0 +1,35 @@
+package session
+
+import "github.com/antonyho/go-congkit/engine"
+
+// Learner records the candidate selected for the radicals,
+// and reverses the record when the commit is undone.
+// *engine.Engine is a Learner.
+type Learner interface {
+	Learn(radicals, text string) error
+	Unlearn(radicals, text string) error
+}
+
+var _ Learner = (*engine.Engine)(nil)
+
+// learn records the selection of the candidate for the radicals
+// if the encoder is a Learner. Associated phrases are not recorded.
+func (s *Session) learn(radicals, candidate string) error {
+	learner, ok := s.encoder.(Learner)
+	if !ok || radicals == "" {
+		return nil
+	}
+
+	return learner.Learn(radicals, candidate)
+}
+
+// unlearn reverses the record of the candidate for the radicals
+// if the encoder is a Learner.
+func (s *Session) unlearn(radicals, candidate string) error {
+	learner, ok := s.encoder.(Learner)
+	if !ok || radicals == "" {
+		return nil
+	}
+
+	return learner.Unlearn(radicals, candidate)
+}
@@ -0,0 +1,93 @@
+package session_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLearner is a fakeAssociator which also records the selections.
+type fakeLearner struct {
+	*fakeAssociator
+	learned []string
+	err     error
+}
+
+func (f *fakeLearner) Learn(radicals, text string) error {
+	f.learned = append(f.learned, radicals+" "+text)
+
+	return f.err
+}
+
+func (f *fakeLearner) Unlearn(radicals, text string) error {
+	f.learned = slices.DeleteFunc(f.learned, func(learned string) bool {
+		return learned == radicals+" "+text
+	})
+
+	return f.err
+}
+
+func TestSessionLearn(t *testing.T) {
+	encoder := &fakeLearner{fakeAssociator: newFakeAssociator()}
+	s := session.New(encoder, session.WithAutoCommit(session.AutoCommitMaxLength))
+
+	press(t, s, 'h', 'q', 'i', '2')
+	press(t, s, 'y', 'k', 'm', 'h', 'm')
+	assert.Equal(t, []string{"hqi 牫", "ykmhm 產"}, encoder.learned)
+}
+
+// punctuatingLearner is a fakeLearner which also lists full-width punctuation.
+type punctuatingLearner struct {
+	*fakeLearner
+}
+
+func (p punctuatingLearner) Punctuation(key rune) ([]rune, error) {
+	return (&fakePunctuator{}).Punctuation(key)
+}
+
+func TestSessionLearnPunctuation(t *testing.T) {
+	encoder := &fakeLearner{fakeAssociator: newFakeAssociator()}
+	s := session.New(punctuatingLearner{encoder}, session.WithFullWidthPunctuation())
+
+	events := press(t, s, 'o', 'i', 'a', 'r', ';')
+	assert.Equal(t, "倉；", commits(events))
+	assert.Equal(t, []string{"oiar 倉"}, encoder.learned, "committing by a punctuation is learned")
+}
+
+func TestSessionUnlearnOnUndo(t *testing.T) {
+	encoder := &fakeLearner{fakeAssociator: newFakeAssociator()}
+	s := session.New(encoder, session.WithAssociation())
+
+	press(t, s, 'o', 'i', 'a', 'r', '1', 'h', 'q', 'i', '2')
+	assert.Equal(t, []string{"oiar 倉", "hqi 牫"}, encoder.learned)
+
+	undo(t, s)
+	assert.Equal(t, []string{"oiar 倉"}, encoder.learned, "the undone selection is unlearned")
+
+	press(t, s, session.KeyEscape, 'a', '2', '1')
+	undo(t, s)
+	assert.Equal(t, []string{"oiar 倉", "a 香"}, encoder.learned, "associated phrases are not unlearned")
+}
+
+func TestSessionLearnAssociation(t *testing.T) {
+	encoder := &fakeLearner{fakeAssociator: newFakeAssociator()}
+	s := session.New(encoder, session.WithAssociation())
+
+	press(t, s, 'a', '2', '1')
+	assert.Equal(t, []string{"a 香"}, encoder.learned, "associated phrases are not learned")
+}
+
+func TestSessionLearnError(t *testing.T) {
+	learnErr := errors.New("learn failed")
+	encoder := &fakeLearner{fakeAssociator: newFakeAssociator(), err: learnErr}
+	s := session.New(encoder)
+
+	press(t, s, 'o', 'i', 'a', 'r')
+	events, err := s.Press('1')
+	require.ErrorIs(t, err, learnErr)
+	assert.Equal(t, "倉", commits(events), "the candidate is committed regardless")
+}
@@ -0,0 +1,135 @@
+package session
+
+// Mode is the input mode of a session.
+type Mode int
+
+const (
+	// ModeCongkit composes Chinese characters from the radical keys.
+	ModeCongkit Mode = iota
+	// ModeEnglish passes the keys through as Latin text.
+	ModeEnglish
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case ModeCongkit:
+		return "congkit"
+	case ModeEnglish:
+		return "english"
+	}
+
+	return "unknown"
+}
+
+// WithMode sets the initial input mode. The default is ModeCongkit.
+func WithMode(mode Mode) Option {
+	return func(s *Session) {
+		s.mode = mode
+	}
+}
+
+// WithEnglishToggleKey sets the key toggling between ModeCongkit and ModeEnglish.
+// The default is KeyShift.
+func WithEnglishToggleKey(key Key) Option {
+	return func(s *Session) {
+		s.englishToggleKey = key
+	}
+}
+
+// WithFullWidthLatin converts the passed through ASCII keys
+// into full-width characters, e.g. 'A' into 'Ａ' and '1' into '１'.
+func WithFullWidthLatin() Option {
+	return func(s *Session) {
+		s.fullWidthLatin = true
+	}
+}
+
+// Mode is the current input mode.
+func (s *Session) Mode() Mode {
+	return s.mode
+}
+
+// SetMode switches the input mode.
+// Switching to ModeEnglish commits the preedit as Latin text,
+// or the first of the listed punctuation candidates.
+// The events are also delivered to the hooks.
+func (s *Session) SetMode(mode Mode) []Event {
+	events := s.setMode(mode)
+	s.notify(events, nil)
+
+	return events
+}
+
+// setMode switches the input mode.
+func (s *Session) setMode(mode Mode) []Event {
+	if mode == s.mode {
+		return nil
+	}
+
+	events := make([]Event, 0)
+	if mode == ModeEnglish && s.punctuating != 0 {
+		events = append(events, s.selectPunctuation(0)...)
+	}
+	if mode == ModeEnglish && len(s.preedit) > 0 {
+		events = append(events, s.commitPreedit()...)
+	}
+	s.mode = mode
+
+	return append(events, Event{Type: EventModeChanged})
+}
+
+// FullWidthLatin reports whether the full-width Latin mode is on.
+func (s *Session) FullWidthLatin() bool {
+	return s.fullWidthLatin
+}
+
+// SetFullWidthLatin turns the full-width Latin mode on or off.
+// A switch is delivered to the OnModeChanged hooks with the current input mode.
+func (s *Session) SetFullWidthLatin(on bool) {
+	if on == s.fullWidthLatin {
+		return
+	}
+
+	s.fullWidthLatin = on
+	s.notify([]Event{{Type: EventModeChanged}}, nil)
+}
+
+// toggleMode toggles between ModeCongkit and ModeEnglish.
+func (s *Session) toggleMode() []Event {
+	if s.mode == ModeEnglish {
+		return s.setMode(ModeCongkit)
+	}
+
+	return s.setMode(ModeEnglish)
+}
+
+// passthrough passes the key through to the application,
+// or commits its full-width character in the full-width Latin mode.
+func (s *Session) passthrough(key Key) []Event {
+	s.seal()
+	if s.fullWidthLatin {
+		if char, ok := fullWidth(key); ok {
+			return []Event{{Type: EventCommit, Text: string(char)}}
+		}
+	}
+
+	event := Event{Type: EventPassthrough, Key: key}
+	if key >= KeySpace {
+		event.Text = string(rune(key))
+	}
+
+	return []Event{event}
+}
+
+// fullWidth converts the printable ASCII key into its full-width character.
+func fullWidth(key Key) (rune, bool) {
+	switch {
+	case key == KeySpace:
+		return '　', true
+	case key > KeySpace && key < 0x7f:
+		return rune(key) - 0x21 + '！', true
+	}
+
+	return 0, false
+}
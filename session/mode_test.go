@@ -0,0 +1,70 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionEnglishMode(t *testing.T) {
+	s := session.New(newFakeEncoder())
+	assert.Equal(t, session.ModeCongkit, s.Mode())
+
+	events := press(t, s, session.KeyShift)
+	assert.Equal(t, []session.Event{{Type: session.EventModeChanged}}, events)
+	assert.Equal(t, session.ModeEnglish, s.Mode())
+
+	events = press(t, s, 'h', 'i')
+	assert.Equal(t, []session.Event{
+		{Type: session.EventPassthrough, Text: "h", Key: 'h'},
+		{Type: session.EventPassthrough, Text: "i", Key: 'i'},
+	}, events)
+	assert.Empty(t, s.Preedit())
+
+	press(t, s, session.KeyShift)
+	assert.Equal(t, session.ModeCongkit, s.Mode())
+	press(t, s, 'h')
+	assert.Equal(t, "h", s.Preedit())
+}
+
+func TestSessionEnglishModeCommitsPreedit(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	events := press(t, s, 'h', 'q', session.KeyShift)
+	assert.Equal(t, "hq", commits(events))
+	assert.Empty(t, s.Preedit())
+	assert.Equal(t, session.ModeEnglish, s.Mode())
+}
+
+func TestSessionEnglishToggleKey(t *testing.T) {
+	s := session.New(newFakeEncoder(),
+		session.WithMode(session.ModeEnglish),
+		session.WithEnglishToggleKey('`'),
+	)
+	assert.Equal(t, session.ModeEnglish, s.Mode())
+
+	press(t, s, '`')
+	assert.Equal(t, session.ModeCongkit, s.Mode())
+
+	events := press(t, s, session.KeyShift)
+	assert.Equal(t, session.EventPassthrough, events[0].Type)
+	assert.Equal(t, session.ModeCongkit, s.Mode())
+}
+
+func TestSessionFullWidthLatin(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithFullWidthLatin(), session.WithMode(session.ModeEnglish))
+	assert.True(t, s.FullWidthLatin())
+
+	events := press(t, s, 'H', 'i', '!', session.KeySpace, '1', '~', session.KeyEnter)
+	assert.Equal(t, "Ｈｉ！　１～", commits(events))
+	assert.Equal(t, session.EventPassthrough, events[len(events)-1].Type)
+
+	s.SetMode(session.ModeCongkit)
+	events = press(t, s, '1')
+	assert.Equal(t, "１", commits(events))
+
+	s.SetFullWidthLatin(false)
+	events = press(t, s, '1')
+	assert.Equal(t, []session.Event{{Type: session.EventPassthrough, Text: "1", Key: '1'}}, events)
+}
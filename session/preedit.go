@@ -0,0 +1,89 @@
+package session
+
+import (
+	"strings"
+
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// PreeditSegment is a radical of the preedit.
+type PreeditSegment struct {
+	Key      byte   // The radical key 'a' to 'z', or radical.Wildcard
+	Glyph    string // The radical glyph, or radical.WildcardGlyph for a wildcard
+	Name     string // The English name of the radical, empty for a wildcard
+	Wildcard bool   // The segment matches any one radical
+}
+
+// PreeditModel is the preedit for front ends to render,
+// e.g. "hqi" with the cursor after "hq" as "竹手|戈".
+type PreeditModel struct {
+	Segments []PreeditSegment
+	Cursor   int // Number of segments before the cursor
+}
+
+// WithWildcardKey sets the key typing a radical.Wildcard into the preedit,
+// which matches any one radical. There is no wildcard key by default.
+func WithWildcardKey(key Key) Option {
+	return func(s *Session) {
+		s.wildcardKey = key
+	}
+}
+
+// PreeditModel returns the segments of the preedit and the cursor position.
+// The cursor is moved by KeyLeft, KeyRight, KeyHome and KeyEnd, and the radicals
+// are inserted and deleted at the cursor.
+func (s *Session) PreeditModel() PreeditModel {
+	model := PreeditModel{
+		Segments: make([]PreeditSegment, len(s.preedit)),
+		Cursor:   s.cursor,
+	}
+	for i, key := range s.preedit {
+		segment := PreeditSegment{Key: key}
+		if r, ok := radical.Of(rune(key)); ok {
+			segment.Glyph, segment.Name = string(r.Glyph), r.Name
+		} else {
+			segment.Glyph = radical.Render(string(key))
+			segment.Wildcard = key == radical.Wildcard
+		}
+		model.Segments[i] = segment
+	}
+
+	return model
+}
+
+// Code is the radical keys of the preedit, e.g. "hqi".
+func (p PreeditModel) Code() string {
+	var b strings.Builder
+	for _, segment := range p.Segments {
+		b.WriteByte(segment.Key)
+	}
+
+	return b.String()
+}
+
+// Glyphs is the radical glyphs of the preedit, e.g. "竹手戈".
+func (p PreeditModel) Glyphs() string {
+	var b strings.Builder
+	for _, segment := range p.Segments {
+		b.WriteString(segment.Glyph)
+	}
+
+	return b.String()
+}
+
+// Render renders the radical glyphs with the cursor mark at the cursor,
+// e.g. "竹手|戈" with the cursor mark "|".
+func (p PreeditModel) Render(cursorMark string) string {
+	var b strings.Builder
+	for i, segment := range p.Segments {
+		if i == p.Cursor {
+			b.WriteString(cursorMark)
+		}
+		b.WriteString(segment.Glyph)
+	}
+	if p.Cursor == len(p.Segments) {
+		b.WriteString(cursorMark)
+	}
+
+	return b.String()
+}
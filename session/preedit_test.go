@@ -0,0 +1,90 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionPreeditModel(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	press(t, s, 'h', 'q', 'i')
+	model := s.PreeditModel()
+	assert.Equal(t, session.PreeditModel{
+		Segments: []session.PreeditSegment{
+			{Key: 'h', Glyph: "竹", Name: "Bamboo"},
+			{Key: 'q', Glyph: "手", Name: "Hand"},
+			{Key: 'i', Glyph: "戈", Name: "Weapon"},
+		},
+		Cursor: 3,
+	}, model)
+	assert.Equal(t, "hqi", model.Code())
+	assert.Equal(t, "竹手戈", model.Glyphs())
+	assert.Equal(t, "竹手戈|", model.Render("|"))
+
+	s.Reset()
+	assert.Equal(t, session.PreeditModel{Segments: []session.PreeditSegment{}}, s.PreeditModel())
+	assert.Equal(t, "|", s.PreeditModel().Render("|"))
+}
+
+func TestSessionCursor(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	press(t, s, 'h', 'i')
+	events := press(t, s, session.KeyLeft)
+	assert.Equal(t, []session.Event{{Type: session.EventPreeditChanged}}, events)
+	assert.Equal(t, "竹|戈", s.PreeditModel().Render("|"))
+
+	press(t, s, 'q')
+	assert.Equal(t, "hqi", s.Preedit())
+	assert.Equal(t, "竹手|戈", s.PreeditModel().Render("|"))
+	assert.Equal(t, []string{"我", "牫", "𥫻"}, s.Candidates())
+
+	press(t, s, session.KeyBackspace)
+	assert.Equal(t, "hi", s.Preedit())
+	assert.Equal(t, 1, s.PreeditModel().Cursor)
+	assert.Empty(t, s.Candidates())
+
+	press(t, s, session.KeyHome, session.KeyDelete)
+	assert.Equal(t, "i", s.Preedit())
+	assert.Equal(t, 0, s.PreeditModel().Cursor)
+
+	assert.Empty(t, press(t, s, session.KeyLeft), "the cursor stays at the start")
+	press(t, s, 'h', 'q', session.KeyEnd, session.KeyBackspace)
+	assert.Equal(t, "hq", s.Preedit())
+	assert.Equal(t, []string{"秉", "乎"}, s.Candidates())
+}
+
+func TestSessionCursorKeysWithoutPreedit(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	events := press(t, s, session.KeyLeft)
+	assert.Equal(t, []session.Event{{Type: session.EventPassthrough, Key: session.KeyLeft}}, events)
+}
+
+func TestSessionEditRestoresEncodedPrefixes(t *testing.T) {
+	encoder := newFakeEncoder()
+	s := session.New(encoder)
+
+	press(t, s, 'o', 'i', 'a', 'r', session.KeyLeft, session.KeyLeft)
+	calls := encoder.calls
+	press(t, s, session.KeyDelete)
+	assert.Equal(t, "oir", s.Preedit())
+	assert.Equal(t, calls+1, encoder.calls, "only the changed prefixes are encoded")
+}
+
+func TestSessionWildcard(t *testing.T) {
+	encoder := newFakeEncoder()
+	encoder.table["h*i"] = []rune{'我', '牫', '𥫻'}
+	s := session.New(encoder, session.WithWildcardKey('?'))
+
+	press(t, s, 'h', '?', 'i')
+	assert.Equal(t, "h*i", s.Preedit())
+	assert.Equal(t, []string{"我", "牫", "𥫻"}, s.Candidates())
+
+	model := s.PreeditModel()
+	assert.Equal(t, session.PreeditSegment{Key: '*', Glyph: "＊", Wildcard: true}, model.Segments[1])
+	assert.Equal(t, "竹＊戈", model.Glyphs())
+}
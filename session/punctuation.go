@@ -0,0 +1,156 @@
+package session
+
+import (
+	"unicode/utf8"
+
+	"github.com/antonyho/go-congkit/engine"
+)
+
+// Punctuator lists the full-width characters of a punctuation key
+// in priority order. *engine.Engine is a Punctuator.
+type Punctuator interface {
+	Punctuation(key rune) ([]rune, error)
+}
+
+var _ Punctuator = (*engine.Engine)(nil)
+
+// bracketPairs are the opening and the closing brackets typed by the same key.
+// The key gives the opening and the closing bracket in turn.
+var bracketPairs = map[rune]rune{
+	'「': '」',
+	'『': '』',
+	'〈': '〉',
+	'《': '》',
+}
+
+// WithFullWidthPunctuation converts the punctuation keys into full-width punctuation.
+// It needs a Punctuator encoder.
+func WithFullWidthPunctuation() Option {
+	return func(s *Session) {
+		s.fullWidthPunctuation = true
+	}
+}
+
+// FullWidthPunctuation reports whether the full-width punctuation mode is on.
+func (s *Session) FullWidthPunctuation() bool {
+	return s.fullWidthPunctuation
+}
+
+// SetFullWidthPunctuation turns the full-width punctuation mode on or off.
+// A switch is delivered to the OnModeChanged hooks with the current input mode.
+func (s *Session) SetFullWidthPunctuation(on bool) {
+	if on == s.fullWidthPunctuation {
+		return
+	}
+
+	s.fullWidthPunctuation = on
+	s.notify([]Event{{Type: EventModeChanged}}, nil)
+}
+
+// punctuation lists the full-width punctuation of the key in priority order.
+// It lists nothing if the full-width punctuation mode is off.
+func (s *Session) punctuation(key Key) ([]rune, error) {
+	punctuator, ok := s.encoder.(Punctuator)
+	if !s.fullWidthPunctuation || !ok || !isPunctuationKey(key) {
+		return nil, nil
+	}
+
+	return punctuator.Punctuation(rune(key))
+}
+
+// punctuate commits the full-width punctuation of the key, or lists the
+// full-width candidates if the key has more than one.
+// The closing bracket is listed first after the opening bracket of the key.
+func (s *Session) punctuate(key Key, punctuation []rune) []Event {
+	if len(punctuation) == 1 {
+		return s.commitPunctuation(key, string(punctuation[0]))
+	}
+
+	candidates := make([]string, len(punctuation))
+	for i, char := range punctuation {
+		candidates[i] = string(char)
+	}
+	if bracketPairs[punctuation[0]] == punctuation[1] && s.closingBrackets[key] {
+		candidates[0], candidates[1] = candidates[1], candidates[0]
+	}
+	s.punctuating = key
+	s.list.Set(candidates)
+
+	return []Event{{Type: EventCandidatesChanged}}
+}
+
+// pressPunctuating handles the key while the punctuation candidates are listed.
+// Escape and backspace dismiss the candidates, any other key which is not
+// a selection or paging key commits the first candidate and is handled as usual.
+func (s *Session) pressPunctuating(key Key) ([]Event, error) {
+	mapped := s.mapKey(key)
+	if index := indexOf(s.selectionKeys, mapped); index >= 0 {
+		return s.selectPunctuation(index), nil
+	}
+	if indexOf(s.previousPageKeys, mapped) >= 0 {
+		return s.turnPage(s.list.PreviousPage), nil
+	}
+	if indexOf(s.nextPageKeys, mapped) >= 0 {
+		return s.turnPage(s.list.NextPage), nil
+	}
+	if key == KeyEscape || key == KeyBackspace {
+		s.reset()
+		return []Event{{Type: EventCandidatesChanged}}, nil
+	}
+
+	events := s.selectPunctuation(0)
+	composed, err := s.compose(key)
+
+	return append(events, composed...), err
+}
+
+// selectPunctuation commits the punctuation candidate at the index of the current page.
+// Selecting a candidate which does not exist does nothing.
+func (s *Session) selectPunctuation(index int) []Event {
+	candidate, ok := s.list.At(index)
+	if !ok {
+		return nil
+	}
+
+	key := s.punctuating
+	s.reset()
+
+	return append(s.commitPunctuation(key, candidate), Event{Type: EventCandidatesChanged})
+}
+
+// commitPunctuation commits the full-width punctuation of the key.
+// Committing a bracket of the key switches between its opening and closing bracket.
+func (s *Session) commitPunctuation(key Key, text string) []Event {
+	char, _ := utf8.DecodeRuneInString(text)
+	if _, ok := bracketPairs[char]; ok {
+		s.closingBrackets[key] = true
+	} else if isClosingBracket(char) {
+		s.closingBrackets[key] = false
+	}
+	s.seal()
+
+	return []Event{{Type: EventCommit, Text: text}}
+}
+
+// isClosingBracket reports whether the character closes a bracket pair.
+func isClosingBracket(char rune) bool {
+	for _, closing := range bracketPairs {
+		if closing == char {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPunctuationKey reports whether the key is an ASCII punctuation or symbol.
+func isPunctuationKey(key Key) bool {
+	switch {
+	case key <= KeySpace || key >= 0x7f:
+		return false
+	case key >= 'a' && key <= 'z', key >= 'A' && key <= 'Z', key >= '0' && key <= '9':
+		return false
+	}
+
+	return true
+}
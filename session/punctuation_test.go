@@ -0,0 +1,103 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePunctuator is a fakeEncoder which also lists full-width punctuation.
+type fakePunctuator struct {
+	*fakeEncoder
+}
+
+func (f *fakePunctuator) Punctuation(key rune) ([]rune, error) {
+	switch key {
+	case ',':
+		return []rune{'、', '，'}, nil
+	case '\'':
+		return []rune{'「', '」', '〈', '〉', '＇'}, nil
+	case ';':
+		return []rune{'；'}, nil
+	}
+
+	return []rune{}, nil
+}
+
+func TestSessionFullWidthPunctuation(t *testing.T) {
+	s := session.New(&fakePunctuator{newFakeEncoder()}, session.WithFullWidthPunctuation())
+	assert.True(t, s.FullWidthPunctuation())
+
+	events := press(t, s, ';')
+	assert.Equal(t, []session.Event{{Type: session.EventCommit, Text: "；"}}, events)
+
+	events = press(t, s, '~')
+	assert.Equal(t, []session.Event{{Type: session.EventPassthrough, Text: "~", Key: '~'}}, events)
+
+	events = press(t, s, 'h', 'q', 'i', ';')
+	assert.Equal(t, "我；", commits(events), "punctuation commits the first candidate")
+	assert.Empty(t, s.Preedit())
+}
+
+func TestSessionFullWidthPunctuationCandidates(t *testing.T) {
+	s := session.New(&fakePunctuator{newFakeEncoder()}, session.WithFullWidthPunctuation())
+
+	events := press(t, s, ',')
+	assert.Equal(t, []session.Event{{Type: session.EventCandidatesChanged}}, events)
+	assert.Equal(t, []string{"、", "，"}, s.Page().Candidates)
+	assert.Empty(t, s.Preedit())
+
+	events = press(t, s, '2')
+	assert.Equal(t, "，", commits(events), "the other candidates can be selected")
+	assert.Empty(t, s.Candidates())
+
+	events = press(t, s, ',', session.KeySpace)
+	assert.Equal(t, "、", commits(events))
+
+	events = press(t, s, ',', ',', 'h', 'q')
+	assert.Equal(t, "、、", commits(events), "another key commits the first candidate")
+	assert.Equal(t, "hq", s.Preedit())
+	assert.Equal(t, []string{"秉", "乎"}, s.Candidates())
+
+	events = press(t, s, ',')
+	assert.Equal(t, "秉", commits(events), "punctuation ends the composition")
+	assert.Equal(t, []string{"、", "，"}, s.Candidates())
+
+	events = press(t, s, session.KeyEscape)
+	assert.Empty(t, commits(events))
+	assert.Empty(t, s.Candidates())
+}
+
+func TestSessionFullWidthPunctuationPaging(t *testing.T) {
+	s := session.New(&fakePunctuator{newFakeEncoder()}, session.WithFullWidthPunctuation(), session.WithPageSize(2))
+
+	press(t, s, '\'', session.KeyPageDown, session.KeyPageDown)
+	assert.Equal(t, []string{"＇"}, s.Page().Candidates)
+	events := press(t, s, '1')
+	assert.Equal(t, "＇", commits(events))
+}
+
+func TestSessionFullWidthPunctuationPairedQuotes(t *testing.T) {
+	s := session.New(&fakePunctuator{newFakeEncoder()}, session.WithFullWidthPunctuation())
+
+	events := press(t, s, '\'', 'o', 'i', 'a', 'r', session.KeySpace, '\'', session.KeySpace, '\'')
+	assert.Equal(t, "「倉」", commits(events))
+	assert.Equal(t, []string{"「", "」", "〈", "〉", "＇"}, s.Candidates())
+
+	press(t, s, '3')
+	press(t, s, '\'')
+	assert.Equal(t, []string{"」", "「", "〈", "〉", "＇"}, s.Candidates(), "the closing bracket is listed first")
+}
+
+func TestSessionHalfWidthPunctuation(t *testing.T) {
+	s := session.New(&fakePunctuator{newFakeEncoder()})
+	assert.False(t, s.FullWidthPunctuation())
+
+	events := press(t, s, ',')
+	assert.Equal(t, []session.Event{{Type: session.EventPassthrough, Text: ",", Key: ','}}, events)
+
+	s.SetFullWidthPunctuation(true)
+	events = press(t, s, ';')
+	assert.Equal(t, "；", commits(events))
+}
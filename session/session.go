@@ -0,0 +1,508 @@
+// Package session provides a stateful input method session on top of the engine.
+//
+// A session takes key events, keeps the radicals being composed (the preedit),
+// lists the candidates of the preedit and commits the selected characters.
+package session
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/keymap"
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// Encoder lists the characters matching the radicals.
+// *engine.Engine is an Encoder.
+type Encoder interface {
+	Encode(radicals string) ([]rune, error)
+}
+
+// CandidateEncoder lists the candidates of the radicals, which can be
+// characters or phrases. The session prefers a CandidateEncoder to an Encoder.
+// *engine.Engine is a CandidateEncoder.
+type CandidateEncoder interface {
+	Candidates(radicals string) ([]string, error)
+}
+
+var (
+	_ Encoder          = (*engine.Engine)(nil)
+	_ CandidateEncoder = (*engine.Engine)(nil)
+)
+
+// EventType is the type of a session event.
+type EventType int
+
+const (
+	// EventCommit commits the text to the application.
+	EventCommit EventType = iota
+	// EventPreeditChanged tells the preedit has changed.
+	EventPreeditChanged
+	// EventCandidatesChanged tells the candidates have changed.
+	EventCandidatesChanged
+	// EventPassthrough tells the key was not consumed by the session
+	// and should be handled by the application.
+	EventPassthrough
+	// EventModeChanged tells the input mode has changed.
+	EventModeChanged
+	// EventRetract tells the application to delete the text
+	// committed last, which is given in the event.
+	EventRetract
+	// EventError tells an error on handling a key.
+	// It is only delivered to the OnError hooks and the hook channels.
+	EventError
+)
+
+// Event is emitted by the session on handling a key.
+type Event struct {
+	Type EventType
+	Text string // Committed or retracted text, or the character of the passed through key
+	Key  Key    // The passed through key
+}
+
+// Option configures a session.
+type Option func(*Session)
+
+// WithPageSize sets the number of candidates on a page.
+// The page size is limited to the number of selection keys.
+func WithPageSize(size int) Option {
+	return func(s *Session) {
+		s.pageSize = size
+	}
+}
+
+// WithSelectionKeys sets the keys selecting the candidates on a page.
+// Radical keys are taken by the composition and are dropped from the selection keys.
+// The DefaultSelectionKeys are kept if no key is left.
+func WithSelectionKeys(keys ...Key) Option {
+	return func(s *Session) {
+		selectionKeys := make([]Key, 0, len(keys))
+		for _, key := range keys {
+			if _, ok := radicalKey(key); !ok {
+				selectionKeys = append(selectionKeys, key)
+			}
+		}
+		if len(selectionKeys) > 0 {
+			s.selectionKeys = selectionKeys
+		}
+	}
+}
+
+// WithPageKeys sets the keys turning to the previous and the next page.
+func WithPageKeys(previous, next []Key) Option {
+	return func(s *Session) {
+		s.previousPageKeys = previous
+		s.nextPageKeys = next
+	}
+}
+
+// Session is an input method composition.
+// A session is not safe for concurrent use.
+type Session struct {
+	encoder Encoder
+	preedit []byte
+	cursor  int // Number of radicals before the cursor in the preedit
+	// Candidates of each preedit prefix, the last one is the current candidates.
+	// Deleting radicals restores the earlier candidates without encoding again.
+	candidates       [][]string
+	list             *CandidateList
+	pageSize         int
+	selectionKeys    []Key
+	previousPageKeys []Key
+	nextPageKeys     []Key
+	autoCommit       AutoCommit
+
+	fullWidthPunctuation bool
+	closingBrackets      map[Key]bool
+	punctuating          Key // The punctuation key of the listed full-width candidates
+
+	mode             Mode
+	englishToggleKey Key
+	fullWidthLatin   bool
+
+	association bool
+	associating string // The committed text of the listed associated phrases
+	context     string // The last committed characters
+
+	hooks        []Hooks
+	hookChannels []chan<- HookEvent
+	dropped      int // Number of the hook events dropped for full channels
+
+	history     []Commit
+	historySize int
+	undoable    int // Number of the last commits in the history which can be undone
+	undoKey     Key
+
+	keymap      *keymap.Keymap
+	wildcardKey Key
+}
+
+// New creates a session encoding with the encoder.
+func New(encoder Encoder, options ...Option) *Session {
+	s := &Session{
+		encoder:          encoder,
+		preedit:          make([]byte, 0, engine.MaxRadicals),
+		candidates:       make([][]string, 0, engine.MaxRadicals),
+		selectionKeys:    DefaultSelectionKeys,
+		previousPageKeys: DefaultPreviousPageKeys,
+		nextPageKeys:     DefaultNextPageKeys,
+		closingBrackets:  make(map[Key]bool),
+		englishToggleKey: KeyShift,
+		historySize:      DefaultHistorySize,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	if s.pageSize <= 0 || s.pageSize > len(s.selectionKeys) {
+		s.pageSize = len(s.selectionKeys)
+	}
+	s.list = NewCandidateList(s.pageSize)
+
+	return s
+}
+
+// Preedit is the radicals being composed.
+func (s *Session) Preedit() string {
+	return string(s.preedit)
+}
+
+// Candidates lists the characters matching the preedit, the associated phrases
+// of the last commit, or the full-width candidates of a punctuation key.
+func (s *Session) Candidates() []string {
+	return s.list.All()
+}
+
+// Page returns the current page of the candidates.
+func (s *Session) Page() Page {
+	page := s.list.Page()
+	page.Labels = s.selectionKeys[:min(len(s.selectionKeys), len(page.Candidates))]
+
+	return page
+}
+
+// Reset clears the composition and the associated phrases.
+// The change of the candidates is delivered to the hooks.
+func (s *Session) Reset() {
+	if len(s.preedit) == 0 && len(s.list.All()) == 0 {
+		s.reset()
+		return
+	}
+
+	s.reset()
+	s.notify([]Event{{Type: EventPreeditChanged}, {Type: EventCandidatesChanged}}, nil)
+}
+
+// reset clears the composition, the associated phrases and the punctuation candidates.
+func (s *Session) reset() {
+	s.preedit = s.preedit[:0]
+	s.cursor = 0
+	s.candidates = s.candidates[:0]
+	s.associating = ""
+	s.punctuating = 0
+	s.list.Set(nil)
+}
+
+// Press handles a key event and returns the resulting events.
+// On error the composition is left as before the key, except that the text
+// committed by the key is still returned with the error on listing its
+// associated phrases. The events and the error are also delivered to the hooks.
+func (s *Session) Press(key Key) ([]Event, error) {
+	events, err := s.press(key)
+	s.notify(events, err)
+
+	return events, err
+}
+
+// press handles the key event.
+func (s *Session) press(key Key) ([]Event, error) {
+	if key == s.englishToggleKey {
+		return s.toggleMode(), nil
+	}
+	if s.mode == ModeEnglish {
+		return s.passthrough(key), nil
+	}
+	if s.undoKey != 0 && key == s.undoKey {
+		return s.undo()
+	}
+
+	if s.associating != "" {
+		mapped := s.mapKey(key)
+		if index := indexOf(s.selectionKeys, mapped); index >= 0 {
+			return s.selectCandidate(index)
+		}
+		if indexOf(s.previousPageKeys, mapped) >= 0 {
+			return s.turnPage(s.list.PreviousPage), nil
+		}
+		if indexOf(s.nextPageKeys, mapped) >= 0 {
+			return s.turnPage(s.list.NextPage), nil
+		}
+
+		// Any other key dismisses the associated phrases.
+		s.reset()
+		if key == KeyEscape {
+			return []Event{{Type: EventCandidatesChanged}}, nil
+		}
+		events, err := s.compose(key)
+		if err != nil {
+			return nil, err
+		}
+		return append([]Event{{Type: EventCandidatesChanged}}, events...), nil
+	}
+	if s.punctuating != 0 {
+		return s.pressPunctuating(key)
+	}
+
+	return s.compose(key)
+}
+
+// compose handles the key for the composition.
+func (s *Session) compose(key Key) ([]Event, error) {
+	mapped := s.mapKey(key)
+	if code, ok := radicalKey(mapped); ok {
+		return s.addRadical(code)
+	}
+	if s.wildcardKey != 0 && key == s.wildcardKey {
+		return s.addRadical(radical.Wildcard)
+	}
+
+	if len(s.preedit) == 0 {
+		punctuation, err := s.punctuation(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(punctuation) > 0 {
+			return s.punctuate(key, punctuation), nil
+		}
+		return s.passthrough(key), nil
+	}
+
+	switch key {
+	case KeyBackspace:
+		return s.deleteRadical(s.cursor - 1)
+	case KeyDelete:
+		return s.deleteRadical(s.cursor)
+	case KeyLeft:
+		return s.moveCursor(s.cursor - 1), nil
+	case KeyRight:
+		return s.moveCursor(s.cursor + 1), nil
+	case KeyHome:
+		return s.moveCursor(0), nil
+	case KeyEnd:
+		return s.moveCursor(len(s.preedit)), nil
+	case KeyEscape:
+		s.reset()
+		return []Event{{Type: EventPreeditChanged}, {Type: EventCandidatesChanged}}, nil
+	case KeySpace:
+		return s.selectCandidate(0)
+	}
+
+	if index := indexOf(s.selectionKeys, mapped); index >= 0 {
+		return s.selectCandidate(index)
+	}
+	if indexOf(s.previousPageKeys, mapped) >= 0 {
+		return s.turnPage(s.list.PreviousPage), nil
+	}
+	if indexOf(s.nextPageKeys, mapped) >= 0 {
+		return s.turnPage(s.list.NextPage), nil
+	}
+
+	if key == KeyEnter {
+		return s.commitPreedit(), nil
+	}
+
+	// Any other key ends the composition with the first candidate, or with the
+	// preedit without candidates, before the key is punctuated or passed through.
+	punctuation, err := s.punctuation(key)
+	if err != nil {
+		return nil, err
+	}
+
+	_, events, err := s.commitCandidate(0)
+	s.seal()
+	if events == nil {
+		events = s.commitPreedit()
+	}
+	if len(punctuation) == 0 {
+		return append(events, s.passthrough(key)...), err
+	}
+
+	return append(events, s.punctuate(key, punctuation)...), err
+}
+
+// commitPreedit commits the radicals of the preedit as Latin text.
+func (s *Session) commitPreedit() []Event {
+	text := string(s.preedit)
+	s.reset()
+	s.seal()
+
+	return []Event{
+		{Type: EventCommit, Text: text},
+		{Type: EventPreeditChanged},
+		{Type: EventCandidatesChanged},
+	}
+}
+
+// addRadical inserts the radical at the cursor and encodes the new preedit.
+func (s *Session) addRadical(code byte) ([]Event, error) {
+	if len(s.preedit) >= engine.MaxRadicals {
+		return nil, nil
+	}
+
+	preedit, candidates, cursor := slices.Clone(s.preedit), slices.Clone(s.candidates), s.cursor
+	if err := s.setPreedit(slices.Insert(slices.Clone(s.preedit), s.cursor, code), s.cursor, s.cursor+1); err != nil {
+		return nil, err
+	}
+
+	autoCommit, err := s.shouldAutoCommit()
+	if err != nil {
+		s.preedit, s.candidates, s.cursor = preedit, candidates, cursor
+		s.showCandidates()
+		return nil, err
+	}
+	if autoCommit {
+		return s.selectCandidate(0)
+	}
+
+	return []Event{{Type: EventPreeditChanged}, {Type: EventCandidatesChanged}}, nil
+}
+
+// setPreedit replaces the preedit and moves the cursor. The candidates of
+// the unchanged leading radicals are kept, only the longer prefixes are encoded.
+// The composition is left as before on error.
+func (s *Session) setPreedit(preedit []byte, unchanged, cursor int) error {
+	candidates := slices.Clone(s.candidates[:unchanged])
+	for i := unchanged; i < len(preedit); i++ {
+		prefixCandidates, err := s.encode(string(preedit[:i+1]))
+		if err != nil {
+			return err
+		}
+		candidates = append(candidates, prefixCandidates)
+	}
+
+	s.preedit = preedit
+	s.candidates = candidates
+	s.cursor = cursor
+	s.showCandidates()
+
+	return nil
+}
+
+// showCandidates lists the candidates of the whole preedit.
+func (s *Session) showCandidates() {
+	if len(s.candidates) > 0 {
+		s.list.Set(s.candidates[len(s.candidates)-1])
+	} else {
+		s.list.Set(nil)
+	}
+}
+
+// encode lists the candidates of the radicals.
+func (s *Session) encode(radicals string) ([]string, error) {
+	if encoder, ok := s.encoder.(ContextEncoder); ok {
+		return encoder.CandidatesAfter(radicals, s.context)
+	}
+	if encoder, ok := s.encoder.(CandidateEncoder); ok {
+		return encoder.Candidates(radicals)
+	}
+
+	chars, err := s.encoder.Encode(radicals)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]string, len(chars))
+	for i, char := range chars {
+		candidates[i] = string(char)
+	}
+
+	return candidates, nil
+}
+
+// deleteRadical removes the radical at the index of the preedit.
+// Deleting the last radical restores the earlier candidates without encoding.
+func (s *Session) deleteRadical(index int) ([]Event, error) {
+	if index < 0 || index >= len(s.preedit) {
+		return nil, nil
+	}
+
+	cursor := s.cursor
+	if index < cursor {
+		cursor--
+	}
+	if err := s.setPreedit(slices.Delete(slices.Clone(s.preedit), index, index+1), index, cursor); err != nil {
+		return nil, err
+	}
+
+	return []Event{{Type: EventPreeditChanged}, {Type: EventCandidatesChanged}}, nil
+}
+
+// moveCursor moves the cursor to the position in the preedit.
+func (s *Session) moveCursor(position int) []Event {
+	position = min(max(position, 0), len(s.preedit))
+	if position == s.cursor {
+		return nil
+	}
+	s.cursor = position
+
+	return []Event{{Type: EventPreeditChanged}}
+}
+
+// turnPage turns the candidate page.
+func (s *Session) turnPage(turn func() bool) []Event {
+	if !turn() {
+		return nil
+	}
+
+	return []Event{{Type: EventCandidatesChanged}}
+}
+
+// selectCandidate commits the candidate at the index of the current page,
+// then lists the associated phrases if the association is on.
+// Selecting a candidate which does not exist does nothing.
+func (s *Session) selectCandidate(index int) ([]Event, error) {
+	committed, events, err := s.commitCandidate(index)
+	if events == nil {
+		return nil, nil
+	}
+
+	return events, errors.Join(err, s.associate(committed))
+}
+
+// commitCandidate commits the candidate at the index of the current page,
+// clears the composition and records the selection if the encoder is a Learner.
+// It returns the committed text, including the text of the associated phrases,
+// and no events if there is no such candidate. The candidate is committed
+// even if the selection cannot be recorded.
+func (s *Session) commitCandidate(index int) (string, []Event, error) {
+	candidate, ok := s.list.At(index)
+	if !ok {
+		return "", nil, nil
+	}
+
+	radicals := string(s.preedit)
+	committed := s.associating + candidate
+	s.record(candidate)
+	s.reset()
+	s.remember(candidate)
+
+	return committed, []Event{
+		{Type: EventCommit, Text: candidate},
+		{Type: EventPreeditChanged},
+		{Type: EventCandidatesChanged},
+	}, s.learn(radicals, candidate)
+}
+
+// radicalKey converts the key to a radical 'a' to 'z'.
+// Keys of radical glyphs, like '日', are taken as their radicals.
+func radicalKey(key Key) (byte, bool) {
+	if key >= 'a' && key <= 'z' {
+		return byte(key), true
+	}
+	if code, ok := radical.Key(rune(key)); ok {
+		return byte(code), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,318 @@
+package session_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEncoder encodes from a fixed table and counts the encodings.
+type fakeEncoder struct {
+	table map[string][]rune
+	calls int
+	err   error
+}
+
+func (f *fakeEncoder) Encode(radicals string) ([]rune, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	candidates, ok := f.table[radicals]
+	if !ok {
+		return []rune{}, nil
+	}
+
+	return candidates, nil
+}
+
+func newFakeEncoder() *fakeEncoder {
+	return &fakeEncoder{
+		table: map[string][]rune{
+			"h":     {'竹'},
+			"hq":    {'秉', '乎'},
+			"hqi":   {'我', '牫', '𥫻'},
+			"o":     {'人'},
+			"oi":    {'代'},
+			"oia":   {'㑁'},
+			"oiar":  {'倉'},
+			"ykmhm": {'產'},
+		},
+	}
+}
+
+func press(t *testing.T, s *session.Session, keys ...session.Key) []session.Event {
+	t.Helper()
+	events := make([]session.Event, 0)
+	for _, key := range keys {
+		keyEvents, err := s.Press(key)
+		require.NoError(t, err)
+		events = append(events, keyEvents...)
+	}
+
+	return events
+}
+
+func commits(events []session.Event) string {
+	text := ""
+	for _, event := range events {
+		if event.Type == session.EventCommit {
+			text += event.Text
+		}
+	}
+
+	return text
+}
+
+func TestSessionCompose(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	events := press(t, s, 'h', 'q', 'i')
+	assert.Equal(t, "hqi", s.Preedit())
+	assert.Equal(t, []string{"我", "牫", "𥫻"}, s.Candidates())
+	assert.Empty(t, commits(events))
+
+	events = press(t, s, session.KeySpace)
+	assert.Equal(t, "我", commits(events))
+	assert.Empty(t, s.Preedit())
+	assert.Empty(t, s.Candidates())
+}
+
+func TestSessionSelectByDigit(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	events := press(t, s, 'h', 'q', 'i', '2')
+	assert.Equal(t, "牫", commits(events))
+
+	events = press(t, s, 'h', 'q', 'i', '9')
+	assert.Empty(t, commits(events), "no candidate at 9")
+	assert.Equal(t, "hqi", s.Preedit())
+}
+
+func TestSessionRadicalGlyphKeys(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	press(t, s, '竹', '手', '戈')
+	assert.Equal(t, "hqi", s.Preedit())
+}
+
+func TestSessionBackspace(t *testing.T) {
+	encoder := newFakeEncoder()
+	s := session.New(encoder)
+
+	press(t, s, 'o', 'i', 'a', 'r')
+	assert.Equal(t, []string{"倉"}, s.Candidates())
+	calls := encoder.calls
+
+	press(t, s, session.KeyBackspace)
+	assert.Equal(t, "oia", s.Preedit())
+	assert.Equal(t, []string{"㑁"}, s.Candidates())
+	assert.Equal(t, calls, encoder.calls, "candidates should be restored without encoding")
+
+	press(t, s, session.KeyBackspace, session.KeyBackspace, session.KeyBackspace)
+	assert.Empty(t, s.Preedit())
+	assert.Empty(t, s.Candidates())
+
+	events := press(t, s, session.KeyBackspace)
+	assert.Equal(t, []session.Event{{Type: session.EventPassthrough, Key: session.KeyBackspace}}, events)
+}
+
+func TestSessionEscape(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	press(t, s, 'h', 'q', session.KeyEscape)
+	assert.Empty(t, s.Preedit())
+	assert.Empty(t, s.Candidates())
+}
+
+func TestSessionMaxRadicals(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	press(t, s, 'y', 'k', 'm', 'h', 'm', 'a')
+	assert.Equal(t, "ykmhm", s.Preedit())
+	assert.Equal(t, []string{"產"}, s.Candidates())
+}
+
+func TestSessionPassthrough(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	events := press(t, s, '1', session.KeySpace)
+	assert.Equal(t, []session.Event{
+		{Type: session.EventPassthrough, Text: "1", Key: '1'},
+		{Type: session.EventPassthrough, Text: " ", Key: session.KeySpace},
+	}, events)
+}
+
+func TestSessionEnterCommitsPreedit(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	events := press(t, s, 'h', 'q', session.KeyEnter)
+	assert.Equal(t, []session.Event{
+		{Type: session.EventCommit, Text: "hq"},
+		{Type: session.EventPreeditChanged},
+		{Type: session.EventCandidatesChanged},
+	}, events[len(events)-3:], "enter commits the radicals and is not passed through")
+	assert.Empty(t, s.Preedit())
+	assert.Empty(t, s.Candidates())
+}
+
+func TestSessionUnhandledKeyEndsComposition(t *testing.T) {
+	s := session.New(newFakeEncoder())
+
+	events := press(t, s, 'h', 'q', 'A')
+	assert.Equal(t, []session.Event{
+		{Type: session.EventCommit, Text: "秉"},
+		{Type: session.EventPreeditChanged},
+		{Type: session.EventCandidatesChanged},
+		{Type: session.EventPassthrough, Text: "A", Key: 'A'},
+	}, events[len(events)-4:], "the composition is committed before the key is passed through")
+	assert.Empty(t, s.Preedit())
+
+	events = press(t, s, 'z', '\t')
+	assert.Equal(t, "z", commits(events), "the preedit is committed without candidates")
+	assert.Equal(t, session.EventPassthrough, events[len(events)-1].Type)
+}
+
+func TestSessionEncodeError(t *testing.T) {
+	encoder := newFakeEncoder()
+	s := session.New(encoder)
+	press(t, s, 'h')
+
+	encoder.err = errors.New("encode failed")
+	_, err := s.Press('q')
+	assert.Error(t, err)
+	assert.Equal(t, "h", s.Preedit())
+	assert.Equal(t, []string{"竹"}, s.Candidates())
+}
+
+func TestSessionPaging(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithPageSize(2))
+
+	press(t, s, 'h', 'q', 'i')
+	assert.Equal(t, session.Page{
+		Index:      0,
+		Count:      2,
+		Candidates: []string{"我", "牫"},
+		Labels:     []session.Key{'1', '2'},
+	}, s.Page())
+
+	events := press(t, s, session.KeyPageDown)
+	assert.Equal(t, []session.Event{{Type: session.EventCandidatesChanged}}, events)
+	assert.Equal(t, session.Page{
+		Index:      1,
+		Count:      2,
+		Candidates: []string{"𥫻"},
+		Labels:     []session.Key{'1'},
+	}, s.Page())
+
+	events = press(t, s, session.KeyPageDown)
+	assert.Empty(t, events, "no more page")
+
+	press(t, s, session.KeyPageUp)
+	assert.Equal(t, 0, s.Page().Index)
+
+	press(t, s, '=')
+	events = press(t, s, session.KeySpace)
+	assert.Equal(t, "𥫻", commits(events), "space commits the first candidate on the page")
+}
+
+func TestSessionCustomKeys(t *testing.T) {
+	s := session.New(newFakeEncoder(),
+		session.WithSelectionKeys('!', '@'),
+		session.WithPageKeys([]session.Key{','}, []session.Key{'.'}),
+	)
+
+	press(t, s, 'h', 'q', 'i')
+	assert.Equal(t, []session.Key{'!', '@'}, s.Page().Labels)
+
+	press(t, s, '.')
+	assert.Equal(t, 1, s.Page().Index)
+	press(t, s, ',')
+	assert.Equal(t, 0, s.Page().Index)
+
+	events := press(t, s, '@')
+	assert.Equal(t, "牫", commits(events))
+}
+
+func TestSessionPageSizeLimitedBySelectionKeys(t *testing.T) {
+	s := session.New(newFakeEncoder(),
+		session.WithPageSize(5),
+		session.WithSelectionKeys('1', '2'),
+	)
+
+	press(t, s, 'h', 'q', 'i')
+	assert.Len(t, s.Page().Candidates, 2)
+}
+
+func TestSessionEmptySelectionKeys(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithSelectionKeys())
+
+	press(t, s, 'h', 'q', 'i')
+	assert.Equal(t, session.DefaultSelectionKeys[:3], s.Page().Labels, "the default selection keys are kept")
+}
+
+func TestSessionRadicalSelectionKeys(t *testing.T) {
+	s := session.New(newFakeEncoder(), session.WithSelectionKeys('a', '!', '日', '@'))
+
+	press(t, s, 'h', 'q', 'i')
+	assert.Equal(t, []session.Key{'!', '@'}, s.Page().Labels, "radical keys are dropped")
+	assert.Equal(t, "hqi", s.Preedit())
+
+	s = session.New(newFakeEncoder(), session.WithSelectionKeys('a', 's'))
+	press(t, s, 'h', 'q', 'i')
+	assert.Equal(t, session.DefaultSelectionKeys[:3], s.Page().Labels)
+}
+
+// fakeCandidateEncoder is a fakeEncoder which also lists phrases.
+type fakeCandidateEncoder struct {
+	*fakeEncoder
+	phrases map[string][]string
+}
+
+func (f *fakeCandidateEncoder) Candidates(radicals string) ([]string, error) {
+	chars, err := f.Encode(radicals)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]string, 0, len(chars))
+	for _, char := range chars {
+		candidates = append(candidates, string(char))
+	}
+
+	return append(candidates, f.phrases[radicals]...), nil
+}
+
+func TestSessionPhraseCandidates(t *testing.T) {
+	s := session.New(&fakeCandidateEncoder{
+		fakeEncoder: newFakeEncoder(),
+		phrases:     map[string][]string{"hq": {"我們"}},
+	})
+
+	press(t, s, 'h', 'q')
+	assert.Equal(t, []string{"秉", "乎", "我們"}, s.Candidates())
+
+	events := press(t, s, '3')
+	assert.Equal(t, "我們", commits(events))
+}
+
+func TestSessionLongPhraseCode(t *testing.T) {
+	eng := engine.New(engine.WithDatabase("../engine/testdata/congkit.db"), engine.WithPhraseInput())
+	defer eng.Close()
+	s := session.New(eng)
+
+	// The first radicals of 輸入法, its first and last radicals "jnohei" are too long to type.
+	press(t, s, 'j', 'o', 'e')
+	index := slices.Index(s.Page().Candidates, "輸入法")
+	require.GreaterOrEqual(t, index, 0)
+	events := press(t, s, s.Page().Labels[index])
+	assert.Equal(t, "輸入法", commits(events))
+
+	press(t, s, 'j', 'n', 'o', 'h', 'e', 'i')
+	assert.Equal(t, "jnohe", s.Preedit())
+	assert.NotContains(t, s.Candidates(), "輸入法")
+}
@@ -0,0 +1,493 @@
+package session
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"unsafe"
+
+	"github.com/antonyho/go-congkit/engine"
+	"github.com/antonyho/go-congkit/radical"
+)
+
+// SnapshotVersion is the version of the snapshot formats written by this package.
+// Version 2 adds the context and version 3 adds the punctuation key.
+// Snapshots of the earlier versions are still restored.
+const SnapshotVersion = 3
+
+// minSnapshotVersion is the oldest version of the snapshots restored.
+const minSnapshotVersion = 1
+
+// Errors on restoring a snapshot
+var (
+	ErrSnapshotVersion = errors.New("session: unsupported snapshot version")
+	ErrInvalidSnapshot = errors.New("session: invalid snapshot")
+)
+
+// Snapshot is the state of a session, which can be encoded in JSON
+// or in a compact binary by MarshalBinary.
+// The encoder and the options of the session are not in the snapshot,
+// a snapshot is restored into a session created with the same options.
+type Snapshot struct {
+	Version              int              `json:"version"`
+	Mode                 Mode             `json:"mode"`
+	Preedit              string           `json:"preedit"`
+	Cursor               int              `json:"cursor"`
+	Candidates           [][]string       `json:"candidates"` // Candidates of each preedit prefix
+	List                 []string         `json:"list"`       // The listed candidates, associated phrases or punctuation
+	Page                 int              `json:"page"`
+	Associating          string           `json:"associating,omitempty"`
+	Context              string           `json:"context,omitempty"`
+	FullWidthPunctuation bool             `json:"fullWidthPunctuation"`
+	FullWidthLatin       bool             `json:"fullWidthLatin"`
+	ClosingBrackets      []Key            `json:"closingBrackets,omitempty"` // Keys of the open brackets
+	Punctuating          Key              `json:"punctuating,omitempty"`     // The key of the listed punctuation
+	History              []SnapshotCommit `json:"history,omitempty"`
+	Undoable             int              `json:"undoable"`
+}
+
+// SnapshotCommit is a commit in the history of a snapshot.
+type SnapshotCommit struct {
+	Text        string     `json:"text"`
+	Radicals    string     `json:"radicals"`
+	Candidates  [][]string `json:"candidates"`
+	Associating string     `json:"associating,omitempty"`
+	Context     string     `json:"context,omitempty"`
+}
+
+// Snapshot takes the state of the session.
+func (s *Session) Snapshot() Snapshot {
+	snapshot := Snapshot{
+		Version:              SnapshotVersion,
+		Mode:                 s.mode,
+		Preedit:              string(s.preedit),
+		Cursor:               s.cursor,
+		Candidates:           append([][]string(nil), s.candidates...),
+		List:                 append([]string(nil), s.list.All()...),
+		Page:                 s.list.Page().Index,
+		Associating:          s.associating,
+		Context:              s.context,
+		FullWidthPunctuation: s.fullWidthPunctuation,
+		FullWidthLatin:       s.fullWidthLatin,
+		Undoable:             s.undoable,
+		Punctuating:          s.punctuating,
+	}
+	for key, closing := range s.closingBrackets {
+		if closing {
+			snapshot.ClosingBrackets = append(snapshot.ClosingBrackets, key)
+		}
+	}
+	slices.Sort(snapshot.ClosingBrackets)
+	for _, commit := range s.history {
+		snapshot.History = append(snapshot.History, SnapshotCommit{
+			Text:        commit.Text,
+			Radicals:    commit.Radicals,
+			Candidates:  append([][]string(nil), commit.candidates...),
+			Associating: commit.associating,
+			Context:     commit.context,
+		})
+	}
+
+	return snapshot
+}
+
+// Size estimates the bytes of the state taken by Snapshot without copying it,
+// including the headers of the listed strings and slices. The fixed fields
+// of the session are not counted, an empty session has a zero size.
+func (s *Session) Size() int {
+	size := len(s.preedit) + len(s.associating) + len(s.context) + stackSize(s.candidates)
+	if s.associating != "" || s.punctuating != 0 {
+		// The associated phrases and the punctuation are listed apart from the candidates stack.
+		size += listSize(s.list.All())
+	}
+	size += len(s.history) * int(unsafe.Sizeof(Commit{}))
+	for _, commit := range s.history {
+		size += len(commit.Text) + len(commit.Radicals) + len(commit.associating) + len(commit.context)
+		size += stackSize(commit.candidates)
+	}
+
+	return size
+}
+
+// Sizes of the string and the slice headers
+const (
+	stringHeader = int(unsafe.Sizeof(""))
+	sliceHeader  = int(unsafe.Sizeof([]string(nil)))
+)
+
+func stackSize(stack [][]string) int {
+	size := 0
+	for _, candidates := range stack {
+		size += listSize(candidates)
+	}
+
+	return size
+}
+
+func listSize(candidates []string) int {
+	size := sliceHeader
+	for _, candidate := range candidates {
+		size += stringHeader + len(candidate)
+	}
+
+	return size
+}
+
+// Restore replaces the state of the session with the snapshot.
+// The candidates are restored as they were without encoding again.
+// The session is left unchanged on error.
+// The restored candidates and mode are delivered to the hooks.
+func (s *Session) Restore(snapshot Snapshot) error {
+	if snapshot.Version < minSnapshotVersion || snapshot.Version > SnapshotVersion {
+		return fmt.Errorf("%w: %d", ErrSnapshotVersion, snapshot.Version)
+	}
+	if err := snapshot.validate(); err != nil {
+		return err
+	}
+
+	list := NewCandidateList(s.pageSize)
+	list.Set(slices.Clone(snapshot.List))
+	for i := 0; i < snapshot.Page; i++ {
+		if !list.NextPage() {
+			return fmt.Errorf("%w: page %d out of range", ErrInvalidSnapshot, snapshot.Page)
+		}
+	}
+
+	events := []Event{{Type: EventPreeditChanged}, {Type: EventCandidatesChanged}}
+	if snapshot.Mode != s.mode || snapshot.FullWidthPunctuation != s.fullWidthPunctuation ||
+		snapshot.FullWidthLatin != s.fullWidthLatin {
+		events = append(events, Event{Type: EventModeChanged})
+	}
+	s.mode = snapshot.Mode
+	s.preedit = []byte(snapshot.Preedit)
+	s.cursor = snapshot.Cursor
+	s.candidates = slices.Clone(snapshot.Candidates)
+	s.list = list
+	s.associating = snapshot.Associating
+	s.punctuating = snapshot.Punctuating
+	s.context = snapshot.Context
+	s.fullWidthPunctuation = snapshot.FullWidthPunctuation
+	s.fullWidthLatin = snapshot.FullWidthLatin
+	s.closingBrackets = make(map[Key]bool, len(snapshot.ClosingBrackets))
+	for _, key := range snapshot.ClosingBrackets {
+		s.closingBrackets[key] = true
+	}
+	s.history = make([]Commit, 0, len(snapshot.History))
+	for _, commit := range snapshot.History {
+		s.history = append(s.history, Commit{
+			Text:        commit.Text,
+			Radicals:    commit.Radicals,
+			candidates:  slices.Clone(commit.Candidates),
+			associating: commit.Associating,
+			context:     commit.Context,
+		})
+	}
+	if s.historySize > 0 && len(s.history) > s.historySize {
+		s.history = slices.Clone(s.history[len(s.history)-s.historySize:])
+	}
+	s.undoable = min(snapshot.Undoable, len(s.history))
+	s.notify(events, nil)
+
+	return nil
+}
+
+// validate checks the consistency of the composition and the history in the snapshot.
+// The listed candidates must be the candidates of the whole preedit
+// unless the associated phrases or the punctuation are listed.
+func (snapshot Snapshot) validate() error {
+	for i, commit := range snapshot.History {
+		if err := commit.validate(); err != nil {
+			return fmt.Errorf("%w: commit %d", err, i)
+		}
+	}
+
+	current := last(snapshot.Candidates)
+	switch {
+	case !validRadicals(snapshot.Preedit):
+		return fmt.Errorf("%w: preedit '%s' not radicals", ErrInvalidSnapshot, snapshot.Preedit)
+	case snapshot.Mode != ModeCongkit && snapshot.Mode != ModeEnglish:
+		return fmt.Errorf("%w: mode %d", ErrInvalidSnapshot, snapshot.Mode)
+	case len(snapshot.Preedit) > engine.MaxRadicals:
+		return fmt.Errorf("%w: preedit '%s' too long", ErrInvalidSnapshot, snapshot.Preedit)
+	case len(snapshot.Candidates) != len(snapshot.Preedit):
+		return fmt.Errorf("%w: %d candidate lists for preedit '%s'",
+			ErrInvalidSnapshot, len(snapshot.Candidates), snapshot.Preedit)
+	case snapshot.Cursor < 0 || snapshot.Cursor > len(snapshot.Preedit):
+		return fmt.Errorf("%w: cursor %d out of range", ErrInvalidSnapshot, snapshot.Cursor)
+	case snapshot.Associating != "" && snapshot.Preedit != "":
+		return fmt.Errorf("%w: associated phrases listed with a composition", ErrInvalidSnapshot)
+	case snapshot.Punctuating != 0 && (snapshot.Preedit != "" || snapshot.Associating != ""):
+		return fmt.Errorf("%w: punctuation listed with a composition", ErrInvalidSnapshot)
+	case snapshot.Associating == "" && snapshot.Punctuating == 0 && !slices.Equal(snapshot.List, current):
+		return fmt.Errorf("%w: list not the candidates of preedit '%s'", ErrInvalidSnapshot, snapshot.Preedit)
+	case snapshot.Page < 0, snapshot.Undoable < 0:
+		return ErrInvalidSnapshot
+	}
+
+	return nil
+}
+
+// validate checks that undoing the commit restores a valid composition
+// which lists the committed text.
+func (commit SnapshotCommit) validate() error {
+	switch {
+	case !validRadicals(commit.Radicals) || len(commit.Radicals) > engine.MaxRadicals:
+		return fmt.Errorf("%w: radicals '%s'", ErrInvalidSnapshot, commit.Radicals)
+	case commit.Associating == "" && len(commit.Candidates) != len(commit.Radicals):
+		return fmt.Errorf("%w: %d candidate lists for radicals '%s'",
+			ErrInvalidSnapshot, len(commit.Candidates), commit.Radicals)
+	case commit.Associating != "" && (commit.Radicals != "" || len(commit.Candidates) != 1):
+		return fmt.Errorf("%w: associated phrases with radicals '%s'", ErrInvalidSnapshot, commit.Radicals)
+	case !slices.Contains(last(commit.Candidates), commit.Text):
+		return fmt.Errorf("%w: text '%s' not a candidate", ErrInvalidSnapshot, commit.Text)
+	}
+
+	return nil
+}
+
+// validRadicals reports whether the code has only the radicals 'a' to 'z' and the wildcard.
+func validRadicals(code string) bool {
+	for _, c := range []byte(code) {
+		if (c < 'a' || c > 'z') && c != radical.Wildcard {
+			return false
+		}
+	}
+
+	return true
+}
+
+// last returns the last candidate list of the stack.
+func last(stack [][]string) []string {
+	if len(stack) == 0 {
+		return nil
+	}
+
+	return stack[len(stack)-1]
+}
+
+// MarshalBinary encodes the snapshot in the compact binary format
+// led by the version of the format. The snapshot is always encoded
+// in the current SnapshotVersion.
+func (snapshot Snapshot) MarshalBinary() ([]byte, error) {
+	b := binary.AppendUvarint(nil, SnapshotVersion)
+	b = binary.AppendUvarint(b, uint64(snapshot.Mode))
+	b = appendString(b, snapshot.Preedit)
+	b = binary.AppendUvarint(b, uint64(snapshot.Cursor))
+	b = appendStringLists(b, snapshot.Candidates)
+	b = appendStrings(b, snapshot.List)
+	b = binary.AppendUvarint(b, uint64(snapshot.Page))
+	b = appendString(b, snapshot.Associating)
+	b = appendBool(b, snapshot.FullWidthPunctuation)
+	b = appendBool(b, snapshot.FullWidthLatin)
+	b = binary.AppendUvarint(b, uint64(len(snapshot.ClosingBrackets)))
+	for _, key := range snapshot.ClosingBrackets {
+		b = binary.AppendVarint(b, int64(key))
+	}
+	b = binary.AppendUvarint(b, uint64(len(snapshot.History)))
+	for _, commit := range snapshot.History {
+		b = appendString(b, commit.Text)
+		b = appendString(b, commit.Radicals)
+		b = appendStringLists(b, commit.Candidates)
+		b = appendString(b, commit.Associating)
+		b = appendString(b, commit.Context)
+	}
+	b = binary.AppendUvarint(b, uint64(snapshot.Undoable))
+	b = appendString(b, snapshot.Context)
+	b = binary.AppendVarint(b, int64(snapshot.Punctuating))
+
+	return b, nil
+}
+
+// UnmarshalBinary decodes the snapshot from the binary format of MarshalBinary.
+func (snapshot *Snapshot) UnmarshalBinary(data []byte) error {
+	r := &snapshotReader{data: data}
+	version := r.int()
+	if r.err == nil && (version < minSnapshotVersion || version > SnapshotVersion) {
+		return fmt.Errorf("%w: %d", ErrSnapshotVersion, version)
+	}
+
+	decoded := Snapshot{Version: version}
+	decoded.Mode = Mode(r.int())
+	decoded.Preedit = r.string()
+	decoded.Cursor = r.int()
+	decoded.Candidates = r.stringLists()
+	decoded.List = r.strings()
+	decoded.Page = r.int()
+	decoded.Associating = r.string()
+	decoded.FullWidthPunctuation = r.bool()
+	decoded.FullWidthLatin = r.bool()
+	for n := r.length(); n > 0 && r.err == nil; n-- {
+		decoded.ClosingBrackets = append(decoded.ClosingBrackets, Key(r.varint()))
+	}
+	for n := r.length(); n > 0 && r.err == nil; n-- {
+		commit := SnapshotCommit{
+			Text:        r.string(),
+			Radicals:    r.string(),
+			Candidates:  r.stringLists(),
+			Associating: r.string(),
+		}
+		if version >= 2 {
+			commit.Context = r.string()
+		}
+		decoded.History = append(decoded.History, commit)
+	}
+	decoded.Undoable = r.int()
+	if version >= 2 {
+		decoded.Context = r.string()
+	}
+	if version >= 3 {
+		decoded.Punctuating = Key(r.varint())
+	}
+	if r.err == nil && len(r.data) > 0 {
+		r.err = fmt.Errorf("%w: %d trailing bytes", ErrInvalidSnapshot, len(r.data))
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	*snapshot = decoded
+
+	return nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+
+	return append(b, s...)
+}
+
+func appendStrings(b []byte, list []string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(list)))
+	for _, s := range list {
+		b = appendString(b, s)
+	}
+
+	return b
+}
+
+func appendStringLists(b []byte, lists [][]string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(lists)))
+	for _, list := range lists {
+		b = appendStrings(b, list)
+	}
+
+	return b
+}
+
+func appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
+	}
+
+	return append(b, 0)
+}
+
+// snapshotReader reads the binary snapshot fields,
+// the first error stops all the following reads.
+type snapshotReader struct {
+	data []byte
+	err  error
+}
+
+func (r *snapshotReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = fmt.Errorf("%w: truncated", ErrInvalidSnapshot)
+		return 0
+	}
+	r.data = r.data[n:]
+
+	return v
+}
+
+func (r *snapshotReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = fmt.Errorf("%w: truncated", ErrInvalidSnapshot)
+		return 0
+	}
+	r.data = r.data[n:]
+
+	return v
+}
+
+// int reads a non-negative integer.
+func (r *snapshotReader) int() int {
+	v := r.uvarint()
+	if r.err == nil && v > math.MaxInt32 {
+		r.err = fmt.Errorf("%w: value %d out of range", ErrInvalidSnapshot, v)
+		return 0
+	}
+
+	return int(v)
+}
+
+// length reads a length or a count, which cannot exceed the remaining data.
+func (r *snapshotReader) length() int {
+	n := r.int()
+	if r.err == nil && n > len(r.data) {
+		r.err = fmt.Errorf("%w: truncated", ErrInvalidSnapshot)
+		return 0
+	}
+
+	return n
+}
+
+func (r *snapshotReader) string() string {
+	n := r.length()
+	if r.err != nil {
+		return ""
+	}
+	s := string(r.data[:n])
+	r.data = r.data[n:]
+
+	return s
+}
+
+func (r *snapshotReader) strings() []string {
+	n := r.length()
+	if n == 0 {
+		return nil
+	}
+	list := make([]string, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		list = append(list, r.string())
+	}
+
+	return list
+}
+
+func (r *snapshotReader) stringLists() [][]string {
+	n := r.length()
+	if n == 0 {
+		return nil
+	}
+	lists := make([][]string, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		lists = append(lists, r.strings())
+	}
+
+	return lists
+}
+
+func (r *snapshotReader) bool() bool {
+	if r.err != nil {
+		return false
+	}
+	if len(r.data) == 0 || r.data[0] > 1 {
+		r.err = fmt.Errorf("%w: invalid boolean", ErrInvalidSnapshot)
+		return false
+	}
+	v := r.data[0] == 1
+	r.data = r.data[1:]
+
+	return v
+}
@@ -0,0 +1,204 @@
+package session_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/antonyho/go-congkit/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// composedSession is a session with a composition on the second page,
+// a history, an open bracket and the toggles switched.
+func composedSession(t *testing.T) (*session.Session, *fakePunctuator) {
+	t.Helper()
+	encoder := &fakePunctuator{newFakeEncoder()}
+	encoder.table["y"] = []rune{'一', '二', '三', '四', '五'}
+	s := session.New(encoder, session.WithPageSize(2), session.WithFullWidthPunctuation())
+	s.SetFullWidthLatin(true)
+
+	press(t, s, '\'', 'o', 'i', 'a', 'r', '1', 'y', session.KeyPageDown)
+
+	return s, encoder
+}
+
+func TestSessionSnapshotRestore(t *testing.T) {
+	s, encoder := composedSession(t)
+	snapshot := s.Snapshot()
+	assert.Equal(t, session.SnapshotVersion, snapshot.Version)
+	assert.Equal(t, "y", snapshot.Preedit)
+	assert.Equal(t, 1, snapshot.Page)
+
+	restored := session.New(encoder, session.WithPageSize(2), session.WithFullWidthPunctuation())
+	calls := encoder.calls
+	require.NoError(t, restored.Restore(snapshot))
+	assert.Equal(t, calls, encoder.calls, "the candidates are restored without encoding")
+	assert.Equal(t, s.Preedit(), restored.Preedit())
+	assert.Equal(t, s.Candidates(), restored.Candidates())
+	assert.Equal(t, s.Page(), restored.Page())
+	assert.Equal(t, s.PreeditModel(), restored.PreeditModel())
+	assert.True(t, restored.FullWidthLatin())
+	assert.Equal(t, snapshot, restored.Snapshot())
+
+	// Both sessions go on the same way.
+	for _, key := range []session.Key{'2', '\'', session.KeyBackspace} {
+		expected, err := s.Press(key)
+		require.NoError(t, err)
+		actual, err := restored.Press(key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestSessionSnapshotJSON(t *testing.T) {
+	s, encoder := composedSession(t)
+
+	data, err := json.Marshal(s.Snapshot())
+	require.NoError(t, err)
+	var snapshot session.Snapshot
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+	assert.Equal(t, s.Snapshot(), snapshot)
+
+	restored := session.New(encoder, session.WithPageSize(2))
+	require.NoError(t, restored.Restore(snapshot))
+	assert.Equal(t, s.Page(), restored.Page())
+}
+
+func TestSessionSnapshotBinary(t *testing.T) {
+	s, encoder := composedSession(t)
+
+	data, err := s.Snapshot().MarshalBinary()
+	require.NoError(t, err)
+	jsonData, err := json.Marshal(s.Snapshot())
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(jsonData))
+
+	var snapshot session.Snapshot
+	require.NoError(t, snapshot.UnmarshalBinary(data))
+	assert.Equal(t, s.Snapshot(), snapshot)
+
+	restored := session.New(encoder, session.WithPageSize(2))
+	require.NoError(t, restored.Restore(snapshot))
+	assert.Equal(t, s.Candidates(), restored.Candidates())
+	assert.Equal(t, "倉", undo(t, restored)[0].Text, "the history is restored")
+
+	assert.ErrorIs(t, snapshot.UnmarshalBinary(data[:len(data)-1]), session.ErrInvalidSnapshot)
+	assert.ErrorIs(t, snapshot.UnmarshalBinary(append(data, 0)), session.ErrInvalidSnapshot)
+	assert.ErrorIs(t, snapshot.UnmarshalBinary([]byte{session.SnapshotVersion + 1}), session.ErrSnapshotVersion)
+}
+
+func TestSessionRestoreInvalid(t *testing.T) {
+	s := session.New(newFakeEncoder())
+	press(t, s, 'h', 'q')
+
+	var testCases = []struct {
+		name     string
+		snapshot session.Snapshot
+		err      error
+	}{
+		{"version", session.Snapshot{Version: session.SnapshotVersion + 1}, session.ErrSnapshotVersion},
+		{"candidates", session.Snapshot{Version: 1, Preedit: "hq"}, session.ErrInvalidSnapshot},
+		{"cursor", session.Snapshot{Version: 1, Cursor: 1}, session.ErrInvalidSnapshot},
+		{"page", session.Snapshot{Version: 1, Page: 1}, session.ErrInvalidSnapshot},
+		{"mode", session.Snapshot{Version: 1, Mode: 9}, session.ErrInvalidSnapshot},
+		{"preedit", session.Snapshot{
+			Version: 1, Preedit: "日", Candidates: [][]string{{"日"}, {"日"}, {"日"}}, List: []string{"日"},
+		}, session.ErrInvalidSnapshot},
+		{"list", session.Snapshot{
+			Version: 1, Preedit: "hq", Candidates: [][]string{{"竹"}, {"秉", "乎"}}, List: []string{"evil"},
+		}, session.ErrInvalidSnapshot},
+		{"associating", session.Snapshot{
+			Version: 1, Preedit: "h", Candidates: [][]string{{"竹"}}, List: []string{"evil"}, Associating: "竹",
+		}, session.ErrInvalidSnapshot},
+		{"punctuating", session.Snapshot{
+			Version: 3, Preedit: "h", Candidates: [][]string{{"竹"}}, List: []string{"、"}, Punctuating: ',',
+		}, session.ErrInvalidSnapshot},
+		{"history radicals", session.Snapshot{Version: 1, History: []session.SnapshotCommit{
+			{Text: "日", Radicals: "日", Candidates: [][]string{{"日"}, {"日"}, {"日"}}},
+		}}, session.ErrInvalidSnapshot},
+		{"history text", session.Snapshot{Version: 1, History: []session.SnapshotCommit{
+			{Text: "evil", Radicals: "h", Candidates: [][]string{{"竹"}}},
+		}}, session.ErrInvalidSnapshot},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.ErrorIs(t, s.Restore(testCase.snapshot), testCase.err)
+			assert.Equal(t, "hq", s.Preedit(), "the session is left unchanged")
+			assert.Equal(t, []string{"秉", "乎"}, s.Candidates())
+		})
+	}
+}
+
+func TestSessionSnapshotVersion1(t *testing.T) {
+	s := session.New(newFakeEncoder())
+	press(t, s, 'h', 'q')
+
+	// A version 1 snapshot without history has no context
+	// and no punctuation key at the end.
+	data, err := s.Snapshot().MarshalBinary()
+	require.NoError(t, err)
+	data[0] = 1
+	data = data[:len(data)-2]
+
+	var snapshot session.Snapshot
+	require.NoError(t, snapshot.UnmarshalBinary(data))
+	assert.Equal(t, 1, snapshot.Version)
+
+	restored := session.New(newFakeEncoder())
+	require.NoError(t, restored.Restore(snapshot))
+	assert.Equal(t, "hq", restored.Preedit())
+	assert.Equal(t, []string{"秉", "乎"}, restored.Candidates())
+}
+
+func TestSessionSnapshotContext(t *testing.T) {
+	s := session.New(newFakeEncoder())
+	press(t, s, 'o', 'i', 'a', 'r', '1', 'h', 'q')
+
+	data, err := s.Snapshot().MarshalBinary()
+	require.NoError(t, err)
+	var snapshot session.Snapshot
+	require.NoError(t, snapshot.UnmarshalBinary(data))
+	assert.Equal(t, "倉", snapshot.Context)
+
+	restored := session.New(newFakeEncoder())
+	require.NoError(t, restored.Restore(snapshot))
+	assert.Equal(t, "倉", restored.Context())
+}
+
+func TestSessionSize(t *testing.T) {
+	s := session.New(newFakeEncoder())
+	assert.Zero(t, s.Size())
+	press(t, s, 'h', 'q')
+	// The preedit, then the candidate lists of "h" and "hq" with their slice and string headers
+	assert.Equal(t, 2+(24+16+3)+(24+2*(16+3)), s.Size())
+
+	composed, encoder := composedSession(t)
+	size := composed.Size()
+	assert.Positive(t, size)
+
+	restored := session.New(encoder, session.WithPageSize(2), session.WithFullWidthPunctuation())
+	require.NoError(t, restored.Restore(composed.Snapshot()))
+	assert.Equal(t, size, restored.Size(), "the size follows the snapshot state")
+
+	press(t, composed, session.KeyEscape)
+	assert.Less(t, composed.Size(), size)
+}
+
+func TestSessionSnapshotPunctuation(t *testing.T) {
+	encoder := &fakePunctuator{newFakeEncoder()}
+	s := session.New(encoder, session.WithFullWidthPunctuation())
+	press(t, s, ',')
+
+	data, err := s.Snapshot().MarshalBinary()
+	require.NoError(t, err)
+	var snapshot session.Snapshot
+	require.NoError(t, snapshot.UnmarshalBinary(data))
+	assert.Equal(t, session.Key(','), snapshot.Punctuating)
+
+	restored := session.New(encoder, session.WithFullWidthPunctuation())
+	require.NoError(t, restored.Restore(snapshot))
+	events := press(t, restored, '2')
+	assert.Equal(t, "，", commits(events), "the punctuation candidates are restored")
+}
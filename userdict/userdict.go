@@ -0,0 +1,418 @@
+// Package userdict provides a writable user dictionary of custom mappings,
+// hidden characters and preferred variants, which the engine merges with the system table.
+package userdict
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/antonyho/go-congkit/radical"
+
+	// SQLite3 driver for the user dictionary, the user dictionary uses SQlite3.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Errors on editing the user dictionary
+var (
+	ErrInvalidEntry = errors.New("userdict: invalid entry")
+)
+
+// MaxRadicals is the length of the longest radicals of a mapping,
+// as the input session takes at most 5 radicals.
+const MaxRadicals = 5
+
+const (
+	CreateEntriesTableQuery = `
+	CREATE TABLE IF NOT EXISTS entries (
+		radicals TEXT NOT NULL,
+		text TEXT NOT NULL,
+		PRIMARY KEY (radicals, text)
+	);
+	`
+
+	CreateHiddenTableQuery = `
+	CREATE TABLE IF NOT EXISTS hidden (
+		text TEXT NOT NULL PRIMARY KEY
+	);
+	`
+
+	CreatePreferredTableQuery = `
+	CREATE TABLE IF NOT EXISTS preferred (
+		text TEXT NOT NULL PRIMARY KEY
+	);
+	`
+
+	AddEntryQuery = `INSERT OR IGNORE INTO entries (radicals, text) VALUES (?, ?);`
+
+	RemoveEntryQuery = `DELETE FROM entries WHERE radicals = ? AND text = ?;`
+
+	ListEntriesQuery = `SELECT radicals, text FROM entries ORDER BY rowid;`
+
+	GetTextsFromRadicalsQuery = `SELECT text FROM entries WHERE radicals = ? ORDER BY rowid;`
+
+	HideQuery = `INSERT OR IGNORE INTO hidden (text) VALUES (?);`
+
+	UnhideQuery = `DELETE FROM hidden WHERE text = ?;`
+
+	ListHiddenQuery = `SELECT text FROM hidden ORDER BY rowid;`
+
+	PreferQuery = `INSERT OR IGNORE INTO preferred (text) VALUES (?);`
+
+	UnpreferQuery = `DELETE FROM preferred WHERE text = ?;`
+
+	ListPreferredQuery = `SELECT text FROM preferred ORDER BY rowid;`
+)
+
+// Marks starting the lines of the hidden and the preferred texts in the text format
+const (
+	HiddenMark    = "-"
+	PreferredMark = "+"
+)
+
+// Entry maps the radicals to a custom text, which can be a character or a phrase.
+type Entry struct {
+	Radicals string
+	Text     string
+}
+
+// Dictionary is a user dictionary stored in a SQLite3 database file.
+// A dictionary is safe for concurrent use.
+type Dictionary struct {
+	db *sql.DB
+
+	mu     sync.Mutex
+	hidden map[string]bool // Cached hidden texts, nil until they are looked up
+}
+
+// Open opens the user dictionary at the path, creating it if it does not exist.
+func Open(path string) (*Dictionary, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open user dictionary at %s. %w", path, err)
+	}
+
+	for _, query := range []string{CreateEntriesTableQuery, CreateHiddenTableQuery, CreatePreferredTableQuery} {
+		if _, err := db.Exec(query); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("error creating user dictionary tables. %w", err)
+		}
+	}
+
+	return &Dictionary{db: db}, nil
+}
+
+// Close closes the user dictionary.
+func (d *Dictionary) Close() error {
+	return d.db.Close()
+}
+
+// Add maps the radicals to the text.
+// The radicals can be the keys 'a' to 'z', the radical glyphs or a mix of them,
+// up to MaxRadicals radicals.
+func (d *Dictionary) Add(radicals, text string) error {
+	entry, err := newEntry(radicals, text)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.db.Exec(AddEntryQuery, entry.Radicals, entry.Text); err != nil {
+		return fmt.Errorf("error adding '%s' '%s' into user dictionary. %w", entry.Radicals, entry.Text, err)
+	}
+
+	return nil
+}
+
+// Remove removes the mapping of the radicals to the text.
+func (d *Dictionary) Remove(radicals, text string) error {
+	entry, err := newEntry(radicals, text)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.db.Exec(RemoveEntryQuery, entry.Radicals, entry.Text); err != nil {
+		return fmt.Errorf("error removing '%s' '%s' from user dictionary. %w", entry.Radicals, entry.Text, err)
+	}
+
+	return nil
+}
+
+// Entries lists the mappings in the order they were added.
+func (d *Dictionary) Entries() ([]Entry, error) {
+	rows, err := d.db.Query(ListEntriesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error listing user dictionary entries. %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]Entry, 0)
+	for rows.Next() {
+		var entry Entry
+		if err := rows.Scan(&entry.Radicals, &entry.Text); err != nil {
+			return nil, fmt.Errorf("error listing user dictionary entries. %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Lookup lists the texts mapped from the radicals in the order they were added.
+// The radicals must be in the keys 'a' to 'z'.
+func (d *Dictionary) Lookup(radicals string) ([]string, error) {
+	rows, err := d.db.Query(GetTextsFromRadicalsQuery, radicals)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up '%s' in user dictionary. %w", radicals, err)
+	}
+
+	return scanTexts(rows)
+}
+
+// Hide hides the text from all the results of the engine.
+func (d *Dictionary) Hide(text string) error {
+	if !validText(text) {
+		return fmt.Errorf("%w: text '%s'", ErrInvalidEntry, text)
+	}
+
+	if _, err := d.db.Exec(HideQuery, text); err != nil {
+		return fmt.Errorf("error hiding '%s' in user dictionary. %w", text, err)
+	}
+	d.invalidateHidden()
+
+	return nil
+}
+
+// Unhide shows the hidden text again.
+func (d *Dictionary) Unhide(text string) error {
+	if _, err := d.db.Exec(UnhideQuery, text); err != nil {
+		return fmt.Errorf("error unhiding '%s' in user dictionary. %w", text, err)
+	}
+	d.invalidateHidden()
+
+	return nil
+}
+
+// Hidden lists the hidden texts in the order they were hidden.
+func (d *Dictionary) Hidden() ([]string, error) {
+	rows, err := d.db.Query(ListHiddenQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error listing hidden texts. %w", err)
+	}
+
+	return scanTexts(rows)
+}
+
+// IsHidden reports whether the text is hidden.
+// The hidden texts are cached until a text is hidden or unhidden.
+func (d *Dictionary) IsHidden(text string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.hidden == nil {
+		texts, err := d.Hidden()
+		if err != nil {
+			return false, err
+		}
+		d.hidden = make(map[string]bool, len(texts))
+		for _, hidden := range texts {
+			d.hidden[hidden] = true
+		}
+	}
+
+	return d.hidden[text], nil
+}
+
+// invalidateHidden drops the cached hidden texts.
+func (d *Dictionary) invalidateHidden() {
+	d.mu.Lock()
+	d.hidden = nil
+	d.mu.Unlock()
+}
+
+// Prefer prefers the text, e.g. a variant character, over the other texts.
+// The other texts are no longer preferred.
+func (d *Dictionary) Prefer(text string, others ...string) error {
+	if !validText(text) {
+		return fmt.Errorf("%w: text '%s'", ErrInvalidEntry, text)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction. %w", err)
+	}
+	defer tx.Rollback()
+	for _, other := range others {
+		if _, err := tx.Exec(UnpreferQuery, other); err != nil {
+			return fmt.Errorf("error unpreferring '%s' in user dictionary. %w", other, err)
+		}
+	}
+	if _, err := tx.Exec(PreferQuery, text); err != nil {
+		return fmt.Errorf("error preferring '%s' in user dictionary. %w", text, err)
+	}
+
+	return tx.Commit()
+}
+
+// Unprefer removes the preference of the text.
+func (d *Dictionary) Unprefer(text string) error {
+	if _, err := d.db.Exec(UnpreferQuery, text); err != nil {
+		return fmt.Errorf("error unpreferring '%s' in user dictionary. %w", text, err)
+	}
+
+	return nil
+}
+
+// Preferred lists the preferred texts in the order they were preferred.
+func (d *Dictionary) Preferred() ([]string, error) {
+	rows, err := d.db.Query(ListPreferredQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error listing preferred texts. %w", err)
+	}
+
+	return scanTexts(rows)
+}
+
+// Export writes the user dictionary in the text format.
+// Each line is either the radicals and the text of a mapping separated by a space,
+// the HiddenMark and a hidden text separated by a space,
+// or the PreferredMark and a preferred text separated by a space.
+func (d *Dictionary) Export(w io.Writer) error {
+	entries, err := d.Entries()
+	if err != nil {
+		return err
+	}
+	hidden, err := d.Hidden()
+	if err != nil {
+		return err
+	}
+	preferred, err := d.Preferred()
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, entry := range entries {
+		fmt.Fprintf(bw, "%s %s\n", entry.Radicals, entry.Text)
+	}
+	for _, text := range hidden {
+		fmt.Fprintf(bw, "%s %s\n", HiddenMark, text)
+	}
+	for _, text := range preferred {
+		fmt.Fprintf(bw, "%s %s\n", PreferredMark, text)
+	}
+
+	return bw.Flush()
+}
+
+// Import adds the mappings, the hidden and the preferred texts in the text format of Export.
+// Empty lines and lines starting with '#' are skipped.
+// Nothing is imported if any line is malformed.
+func (d *Dictionary) Import(r io.Reader) error {
+	entries := make([]Entry, 0)
+	hidden := make([]string, 0)
+	preferred := make([]string, 0)
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: %w", lineNum, ErrInvalidEntry)
+		}
+		switch fields[0] {
+		case HiddenMark:
+			if !validText(fields[1]) {
+				return fmt.Errorf("line %d: %w: text '%s'", lineNum, ErrInvalidEntry, fields[1])
+			}
+			hidden = append(hidden, fields[1])
+			continue
+		case PreferredMark:
+			if !validText(fields[1]) {
+				return fmt.Errorf("line %d: %w: text '%s'", lineNum, ErrInvalidEntry, fields[1])
+			}
+			preferred = append(preferred, fields[1])
+			continue
+		}
+		entry, err := newEntry(fields[0], fields[1])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading user dictionary. %w", err)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting db transaction. %w", err)
+	}
+	defer tx.Rollback()
+	for _, entry := range entries {
+		if _, err := tx.Exec(AddEntryQuery, entry.Radicals, entry.Text); err != nil {
+			return fmt.Errorf("error adding '%s' '%s' into user dictionary. %w", entry.Radicals, entry.Text, err)
+		}
+	}
+	for _, text := range hidden {
+		if _, err := tx.Exec(HideQuery, text); err != nil {
+			return fmt.Errorf("error hiding '%s' in user dictionary. %w", text, err)
+		}
+	}
+	for _, text := range preferred {
+		if _, err := tx.Exec(PreferQuery, text); err != nil {
+			return fmt.Errorf("error preferring '%s' in user dictionary. %w", text, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	d.invalidateHidden()
+
+	return nil
+}
+
+// newEntry validates and normalises the radicals and the text of a mapping.
+func newEntry(radicals, text string) (Entry, error) {
+	code, err := radical.Normalize(radicals)
+	if err != nil {
+		return Entry{}, fmt.Errorf("%w: %w", ErrInvalidEntry, err)
+	}
+	if len(code) == 0 || len(code) > MaxRadicals || strings.Trim(code, "abcdefghijklmnopqrstuvwxyz") != "" {
+		return Entry{}, fmt.Errorf("%w: radicals '%s'", ErrInvalidEntry, radicals)
+	}
+	if !validText(text) {
+		return Entry{}, fmt.Errorf("%w: text '%s'", ErrInvalidEntry, text)
+	}
+
+	return Entry{Radicals: code, Text: text}, nil
+}
+
+// validText reports whether the text is not empty and has no white space.
+func validText(text string) bool {
+	return len(text) > 0 && strings.IndexFunc(text, unicode.IsSpace) < 0
+}
+
+func scanTexts(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	texts := make([]string, 0)
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, rows.Err()
+}
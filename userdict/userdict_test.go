@@ -0,0 +1,170 @@
+package userdict_test
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/antonyho/go-congkit/radical"
+	"github.com/antonyho/go-congkit/userdict"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openDictionary(t *testing.T) *userdict.Dictionary {
+	t.Helper()
+	dict, err := userdict.Open(path.Join(t.TempDir(), "user.db"))
+	require.NoError(t, err, "failed opening user dictionary")
+	t.Cleanup(func() { dict.Close() })
+
+	return dict
+}
+
+func TestDictionaryEntries(t *testing.T) {
+	dict := openDictionary(t)
+
+	require.NoError(t, dict.Add("oiar", "倉頡公司"))
+	require.NoError(t, dict.Add("人戈日口", "倉"))
+	require.NoError(t, dict.Add("oiar", "倉頡公司"), "adding an existing entry is ignored")
+	require.NoError(t, dict.Add("hqi", "我"))
+
+	entries, err := dict.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []userdict.Entry{
+		{Radicals: "oiar", Text: "倉頡公司"},
+		{Radicals: "oiar", Text: "倉"},
+		{Radicals: "hqi", Text: "我"},
+	}, entries)
+
+	texts, err := dict.Lookup("oiar")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"倉頡公司", "倉"}, texts)
+
+	require.NoError(t, dict.Remove("oiar", "倉頡公司"))
+	texts, err = dict.Lookup("oiar")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"倉"}, texts)
+}
+
+func TestDictionaryInvalidEntry(t *testing.T) {
+	dict := openDictionary(t)
+
+	assert.ErrorIs(t, dict.Add("", "倉"), userdict.ErrInvalidEntry)
+	assert.ErrorIs(t, dict.Add("oi1", "倉"), userdict.ErrInvalidEntry)
+	assert.ErrorIs(t, dict.Add("oiaroi", "倉"), userdict.ErrInvalidEntry, "more than 5 radicals cannot be typed")
+	assert.ErrorIs(t, dict.Add("oiar", ""), userdict.ErrInvalidEntry)
+	assert.ErrorIs(t, dict.Add("oiar", "倉 頡"), userdict.ErrInvalidEntry)
+	assert.ErrorIs(t, dict.Add("人我", "倉"), radical.ErrUnknownGlyph)
+	assert.ErrorIs(t, dict.Hide(""), userdict.ErrInvalidEntry)
+}
+
+func TestDictionaryHidden(t *testing.T) {
+	dict := openDictionary(t)
+
+	require.NoError(t, dict.Hide("牫"))
+	require.NoError(t, dict.Hide("𥫻"))
+	hidden, err := dict.Hidden()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"牫", "𥫻"}, hidden)
+
+	require.NoError(t, dict.Unhide("牫"))
+	hidden, err = dict.Hidden()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"𥫻"}, hidden)
+}
+
+func TestDictionaryIsHidden(t *testing.T) {
+	dict := openDictionary(t)
+
+	isHidden := func(text string) bool {
+		t.Helper()
+		hidden, err := dict.IsHidden(text)
+		require.NoError(t, err)
+		return hidden
+	}
+
+	assert.False(t, isHidden("牫"))
+	require.NoError(t, dict.Hide("牫"))
+	assert.True(t, isHidden("牫"), "hiding a text refreshes the cached hidden texts")
+	require.NoError(t, dict.Unhide("牫"))
+	assert.False(t, isHidden("牫"), "unhiding a text refreshes the cached hidden texts")
+	require.NoError(t, dict.Import(strings.NewReader("- 𥫻\n")))
+	assert.True(t, isHidden("𥫻"), "importing refreshes the cached hidden texts")
+}
+
+func TestDictionaryPreferred(t *testing.T) {
+	dict := openDictionary(t)
+
+	require.NoError(t, dict.Prefer("産", "產"))
+	require.NoError(t, dict.Prefer("戸", "戶", "户"))
+	preferred, err := dict.Preferred()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"産", "戸"}, preferred)
+
+	require.NoError(t, dict.Prefer("產", "産"), "preferring another variant replaces the preference")
+	require.NoError(t, dict.Unprefer("戸"))
+	preferred, err = dict.Preferred()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"產"}, preferred)
+
+	assert.ErrorIs(t, dict.Prefer(""), userdict.ErrInvalidEntry)
+}
+
+func TestDictionaryPersistence(t *testing.T) {
+	dictPath := path.Join(t.TempDir(), "user.db")
+	dict, err := userdict.Open(dictPath)
+	require.NoError(t, err)
+	require.NoError(t, dict.Add("oiar", "倉頡公司"))
+	require.NoError(t, dict.Close())
+
+	dict, err = userdict.Open(dictPath)
+	require.NoError(t, err)
+	defer dict.Close()
+	texts, err := dict.Lookup("oiar")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"倉頡公司"}, texts)
+}
+
+func TestDictionaryExportImport(t *testing.T) {
+	dict := openDictionary(t)
+	require.NoError(t, dict.Add("oiar", "倉頡公司"))
+	require.NoError(t, dict.Add("hqi", "我"))
+	require.NoError(t, dict.Hide("牫"))
+	require.NoError(t, dict.Prefer("産"))
+
+	var exported bytes.Buffer
+	require.NoError(t, dict.Export(&exported))
+	assert.Equal(t, "oiar 倉頡公司\nhqi 我\n- 牫\n+ 産\n", exported.String())
+
+	imported := openDictionary(t)
+	require.NoError(t, imported.Import(strings.NewReader("# comment\n\n"+exported.String())))
+	entries, err := imported.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []userdict.Entry{
+		{Radicals: "oiar", Text: "倉頡公司"},
+		{Radicals: "hqi", Text: "我"},
+	}, entries)
+	hidden, err := imported.Hidden()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"牫"}, hidden)
+	preferred, err := imported.Preferred()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"産"}, preferred)
+}
+
+func TestDictionaryImportMalformed(t *testing.T) {
+	dict := openDictionary(t)
+
+	err := dict.Import(strings.NewReader("oiar 倉\nhqi\n"))
+	assert.ErrorIs(t, err, userdict.ErrInvalidEntry)
+	assert.ErrorContains(t, err, "line 2")
+
+	err = dict.Import(strings.NewReader("oiar 倉\noiaroi 倉\n"))
+	assert.ErrorIs(t, err, userdict.ErrInvalidEntry)
+	assert.ErrorContains(t, err, "line 2")
+
+	entries, err := dict.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing is imported")
+}